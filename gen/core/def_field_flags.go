@@ -49,3 +49,28 @@ var _ StructFieldItem = defFieldTag{}
 func Tag(key string, value string) defFieldTag {
 	return defFieldTag{key: key, value: value}
 }
+
+type defFieldDefault struct {
+	expr string
+}
+
+func (d defFieldDefault) FieldItem() {}
+
+func (d defFieldDefault) ModelFieldItem(ctx *ModelFieldContext) {
+	ctx.Field.Default = d.expr
+}
+
+func (d defFieldDefault) StructFieldItem(ctx *StructFieldContext) {
+	ctx.Field.Default = d.expr
+}
+
+var _ FieldItem = defFieldDefault{}
+var _ ModelFieldItem = defFieldDefault{}
+var _ StructFieldItem = defFieldDefault{}
+
+// Default sets the SQL expression for the column default, for example "now()".
+// Insert leaves the column out when the field has the zero value, and reads
+// back the value generated by the database.
+func Default(expr string) defFieldDefault {
+	return defFieldDefault{expr: expr}
+}
@@ -1,6 +1,9 @@
 package core
 
-import "github.com/sqlbunny/sqlbunny/schema"
+import (
+	"github.com/sqlbunny/sqlbunny/schema"
+	sqlschema "github.com/sqlbunny/sqlbunny/sqlschema/schema"
+)
 
 type defModelPrimaryKey struct {
 	names []string
@@ -130,15 +133,35 @@ type defModelForeignKey struct {
 	foreignModelName   string
 	columnNames        []string
 	foreignColumnNames []string
+	actions            foreignKeyActions
+}
+
+// OnDelete sets the action run when the referenced row is deleted.
+func (d defModelForeignKey) OnDelete(action sqlschema.ForeignKeyAction) defModelForeignKey {
+	d.actions.onDelete = action
+	return d
+}
+
+// OnUpdate sets the action run when the referenced columns are updated.
+func (d defModelForeignKey) OnUpdate(action sqlschema.ForeignKeyAction) defModelForeignKey {
+	d.actions.onUpdate = action
+	return d
+}
+
+// Deferrable makes the foreign key DEFERRABLE INITIALLY DEFERRED, so it's
+// checked at the end of the transaction instead of after each statement.
+func (d defModelForeignKey) Deferrable() defModelForeignKey {
+	d.actions.deferrable = true
+	return d
 }
 
 func (d defModelForeignKey) ModelItem(ctx *ModelContext) {}
 func (d defModelForeignKey) ModelRecursiveItem(ctx *ModelRecursiveContext) {
 	m := ctx.Model
-	m.ForeignKeys = append(m.ForeignKeys, &schema.ForeignKey{
+	m.ForeignKeys = append(m.ForeignKeys, d.actions.apply(&schema.ForeignKey{
 		LocalFields:  parsePathsPrefix(ctx, ctx.Prefix, d.columnNames),
 		ForeignModel: d.foreignModelName,
-	})
+	}))
 }
 
 var _ ModelItem = defModelForeignKey{}
@@ -154,15 +177,35 @@ func ModelForeignKey(foreignModelName string, columnNames ...string) defModelFor
 
 type defFieldForeignKey struct {
 	foreignModelName string
+	actions          foreignKeyActions
+}
+
+// OnDelete sets the action run when the referenced row is deleted.
+func (d defFieldForeignKey) OnDelete(action sqlschema.ForeignKeyAction) defFieldForeignKey {
+	d.actions.onDelete = action
+	return d
+}
+
+// OnUpdate sets the action run when the referenced columns are updated.
+func (d defFieldForeignKey) OnUpdate(action sqlschema.ForeignKeyAction) defFieldForeignKey {
+	d.actions.onUpdate = action
+	return d
+}
+
+// Deferrable makes the foreign key DEFERRABLE INITIALLY DEFERRED, so it's
+// checked at the end of the transaction instead of after each statement.
+func (d defFieldForeignKey) Deferrable() defFieldForeignKey {
+	d.actions.deferrable = true
+	return d
 }
 
 func (d defFieldForeignKey) FieldItem() {}
 func (d defFieldForeignKey) ModelRecursiveFieldItem(ctx *ModelRecursiveFieldContext) {
 	m := ctx.Model
-	m.ForeignKeys = append(m.ForeignKeys, &schema.ForeignKey{
+	m.ForeignKeys = append(m.ForeignKeys, d.actions.apply(&schema.ForeignKey{
 		LocalFields:  []schema.Path{parsePathPrefix(ctx, ctx.Prefix, ctx.Field.Name)},
 		ForeignModel: d.foreignModelName,
-	})
+	}))
 }
 
 var _ FieldItem = defFieldForeignKey{}
@@ -173,3 +216,57 @@ func ForeignKey(foreignModelName string) defFieldForeignKey {
 		foreignModelName: foreignModelName,
 	}
 }
+
+type defModelCheck struct {
+	name     string
+	expr     string
+	notValid bool
+}
+
+// NotValid adds the check NOT VALID, so adding it doesn't scan the existing
+// rows. Remove it in a later migration to validate them.
+func (d defModelCheck) NotValid() defModelCheck {
+	d.notValid = true
+	return d
+}
+
+func (d defModelCheck) ModelItem(ctx *ModelContext) {
+	ctx.Model.Checks = append(ctx.Model.Checks, &schema.Check{
+		Name:     d.name,
+		Expr:     d.expr,
+		NotValid: d.notValid,
+	})
+}
+
+var _ ModelItem = defModelCheck{}
+
+// Check adds a check constraint. expr is a SQL boolean expression on the
+// columns of the model, for example "price >= 0".
+func Check(name string, expr string) defModelCheck {
+	return defModelCheck{
+		name: name,
+		expr: expr,
+	}
+}
+
+// Foreign key actions, for ForeignKey(...).OnDelete and OnUpdate.
+const (
+	NoAction   = sqlschema.NoAction
+	Restrict   = sqlschema.Restrict
+	Cascade    = sqlschema.Cascade
+	SetNull    = sqlschema.SetNull
+	SetDefault = sqlschema.SetDefault
+)
+
+type foreignKeyActions struct {
+	onDelete   sqlschema.ForeignKeyAction
+	onUpdate   sqlschema.ForeignKeyAction
+	deferrable bool
+}
+
+func (a foreignKeyActions) apply(fk *schema.ForeignKey) *schema.ForeignKey {
+	fk.OnDelete = a.onDelete
+	fk.OnUpdate = a.onUpdate
+	fk.Deferrable = a.deferrable
+	return fk
+}
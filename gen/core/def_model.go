@@ -68,3 +68,17 @@ func Model(name string, items ...ModelItem) gen.ConfigItem {
 		items: items,
 	}
 }
+
+type defModelReturnAll struct{}
+
+func (d defModelReturnAll) ModelItem(ctx *ModelContext) {
+	ctx.Model.ReturnAll = true
+}
+
+var _ ModelItem = defModelReturnAll{}
+
+// ReturnAll makes the generated Insert and Update read back all the columns
+// of the row with RETURNING. Without it, Insert reads back only the columns
+// with a Default it didn't set, and Update reads back nothing. Use it for
+// tables with triggers or generated columns.
+var ReturnAll defModelReturnAll
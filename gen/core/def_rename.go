@@ -0,0 +1,42 @@
+package core
+
+import "fmt"
+
+type defRenamedFrom struct {
+	name string
+}
+
+func (d defRenamedFrom) ModelItem(ctx *ModelContext) {
+	if ctx.Model.RenamedFrom != "" {
+		ctx.AddError("Model '%s' has multiple RenamedFrom definitions", ctx.Model.Name)
+	}
+	ctx.Model.RenamedFrom = d.name
+}
+
+func (d defRenamedFrom) FieldItem() {}
+
+func (d defRenamedFrom) ModelFieldItem(ctx *ModelFieldContext) {
+	if ctx.Field.RenamedFrom != "" {
+		ctx.AddError("%s has multiple RenamedFrom definitions", fmt.Sprintf("model %s field %s", ctx.Model.Name, ctx.Field.Name))
+	}
+	ctx.Field.RenamedFrom = d.name
+}
+
+func (d defRenamedFrom) StructFieldItem(ctx *StructFieldContext) {
+	if ctx.Field.RenamedFrom != "" {
+		ctx.AddError("%s has multiple RenamedFrom definitions", fmt.Sprintf("struct %s field %s", ctx.Struct.Name, ctx.Field.Name))
+	}
+	ctx.Field.RenamedFrom = d.name
+}
+
+var _ ModelItem = defRenamedFrom{}
+var _ FieldItem = defRenamedFrom{}
+var _ ModelFieldItem = defRenamedFrom{}
+var _ StructFieldItem = defRenamedFrom{}
+
+// RenamedFrom marks a model or field as renamed from name. Migrations rename
+// the table or column instead of dropping it and creating a new one.
+// It can be removed once the migration renaming it has been generated.
+func RenamedFrom(name string) defRenamedFrom {
+	return defRenamedFrom{name: name}
+}
@@ -4,12 +4,12 @@
 import (
     "database/sql/driver"
 
-    "github.com/lib/pq"
+    "github.com/sqlbunny/sqlbunny/runtime/bunny"
 )
 
 // {{$arrayName}} is a slice of {{$enumName}} backed by a postgres integer[]
 // column. It implements sql.Scanner / driver.Valuer by delegating to
-// pq.Int32Array, so it interoperates with `= ANY(...)` and `<@ / &&` operators
+// bunny.Array, so it interoperates with `= ANY(...)` and `<@ / &&` operators
 // natively.
 //
 // JSON marshaling falls through to the stdlib's handling of []{{$enumName}} —
@@ -19,8 +19,8 @@ type {{$arrayName}} []{{$enumName}}
 
 // Scan implements the sql.Scanner interface.
 func (a *{{$arrayName}}) Scan(src any) error {
-    var arr pq.Int32Array
-    if err := arr.Scan(src); err != nil {
+    var arr []int64
+    if err := bunny.Array(&arr).Scan(src); err != nil {
         return err
     }
     if arr == nil {
@@ -40,9 +40,9 @@ func (a {{$arrayName}}) Value() (driver.Value, error) {
     if a == nil {
         return "{}", nil
     }
-    arr := make(pq.Int32Array, len(a))
+    arr := make([]int64, len(a))
     for i, v := range a {
-        arr[i] = int32(v)
+        arr[i] = int64(v)
     }
-    return arr.Value()
+    return bunny.Array(arr).Value()
 }
@@ -3,7 +3,9 @@
 {{ import "sql" "database/sql" }}
 {{ import "json" "encoding/json" }}
 {{ import "fmt" "fmt" }}
+{{ import "iter" "iter" }}
 {{ import "reflect" "reflect" }}
+{{ import "slices" "slices" }}
 {{ import "strings" "strings" }}
 {{ import "sync" "sync" }}
 {{ import "time" "time" }}
@@ -4,6 +4,7 @@ var (
 	{{$varNameSingular}}Columns               = []string{{"{"}}{{modelColumns      .Model | stringMap .StringFuncs.quoteWrap | join ", "}}{{"}"}}
 	{{$varNameSingular}}PrimaryKeyColumns     = []string{{"{"}}{{modelPKColumns    .Model | stringMap .StringFuncs.quoteWrap | join ", "}}{{"}"}}
 	{{$varNameSingular}}NonPrimaryKeyColumns  = []string{{"{"}}{{modelNonPKColumns .Model | stringMap .StringFuncs.quoteWrap | join ", "}}{{"}"}}
+	{{$varNameSingular}}DefaultColumns        = []string{{"{"}}{{modelDefaultColumns .Model | stringMap .StringFuncs.quoteWrap | join ", "}}{{"}"}}
 )
 
 type (
@@ -16,13 +17,15 @@ type (
 	}
 )
 
-// Cache for insert, update
+// Cache for insert, upsert, update
 var (
 	{{$varNameSingular}}Type = reflect.TypeOf(&{{$modelNameSingular}}{})
 	{{$varNameSingular}}Mapping = queries.MakeStructMapping({{$varNameSingular}}Type)
 	{{$varNameSingular}}PrimaryKeyMapping, _ = queries.BindMapping({{$varNameSingular}}Type, {{$varNameSingular}}Mapping, {{$varNameSingular}}PrimaryKeyColumns)
 	{{$varNameSingular}}InsertCacheMut sync.RWMutex
 	{{$varNameSingular}}InsertCache = make(map[string]insertCache)
+	{{$varNameSingular}}UpsertCacheMut sync.RWMutex
+	{{$varNameSingular}}UpsertCache = make(map[string]insertCache)
 	{{$varNameSingular}}UpdateCacheMut sync.RWMutex
 	{{$varNameSingular}}UpdateCache = make(map[string]updateCache)
 )
@@ -0,0 +1,13 @@
+{{- $modelNameSingular := .Model.Name | singular | titleCase -}}
+{{- $schemaModel := .Model.Name | schemaModel -}}
+{{- $dot := . -}}
+// {{$modelNameSingular}}Where has typed where clauses for each column of {{.Model.Name}}.
+var {{$modelNameSingular}}Where = struct {
+	{{range modelWhereFields .Model -}}
+	{{titleCase .Column}} {{if .NullStruct}}qm.WhereNullStruct{{else if .Nullable}}qm.WhereNullField[{{goType .GoType}}]{{else}}qm.WhereField[{{goType .GoType}}]{{end}}
+	{{end -}}
+}{
+	{{range modelWhereFields .Model -}}
+	{{titleCase .Column}}: {{if .NullStruct}}qm.NewWhereNullStruct{{else if .Nullable}}qm.NewWhereNullField[{{goType .GoType}}]{{else}}qm.NewWhereField[{{goType .GoType}}]{{end}}("{{$schemaModel}}.{{$dot.LQ}}{{.Column}}{{$dot.RQ}}"),
+	{{end -}}
+}
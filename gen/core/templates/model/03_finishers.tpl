@@ -5,7 +5,7 @@
 func (q {{$varNameSingular}}Query) One(ctx context.Context) (*{{$modelNameSingular}}, error) {
 	o := &{{$modelNameSingular}}{}
 
-	err := q.Bind(ctx, o)
+	err := q.Bind(queries.ReadReplica(ctx, q.Query), o)
 	if err != nil {
 		return nil, errors.Errorf("{{.PkgName}}: failed to execute a one query for {{.Model.Name}}: %w", err)
 	}
@@ -22,7 +22,7 @@ func (q {{$varNameSingular}}Query) First(ctx context.Context) (*{{$modelNameSing
 
 	queries.SetLimit(q.Query, 1)
 
-	err := q.Bind(ctx, o)
+	err := q.Bind(queries.ReadReplica(ctx, q.Query), o)
 	if err != nil {
 		return nil, errors.Errorf("{{.PkgName}}: failed to execute a one query for {{.Model.Name}}: %w", err)
 	}
@@ -36,7 +36,7 @@ func (q {{$varNameSingular}}Query) First(ctx context.Context) (*{{$modelNameSing
 func (q {{$varNameSingular}}Query) All(ctx context.Context) ({{$modelNameSingular}}Slice, error) {
 	var o []*{{$modelNameSingular}}
 
-	err := q.Bind(ctx, &o)
+	err := q.Bind(queries.ReadReplica(ctx, q.Query), &o)
 	if err != nil {
 		return nil, errors.Errorf("{{.PkgName}}: failed to assign all query results to {{$modelNameSingular}} slice: %w", err)
 	}
@@ -46,6 +46,35 @@ func (q {{$varNameSingular}}Query) All(ctx context.Context) ({{$modelNameSingula
 	return o, nil
 }
 
+// Each calls fn for each {{$modelNameSingular}} record from the query, reading the rows one at a time
+// instead of loading them all in memory. If the query loads relationships, they are loaded for
+// chunks of records, of the size set with qm.ChunkSize. The error returned by fn stops the
+// iteration and is returned.
+// Inside a transaction, fn can't run queries and relationships can't be loaded.
+func (q {{$varNameSingular}}Query) Each(ctx context.Context, fn func(*{{$modelNameSingular}}) error) error {
+	return queries.Each(queries.ReadReplica(ctx, q.Query), q.Query, func(o *{{$modelNameSingular}}) error {
+		{{ hook . "after_select_noreturn" "o" .Model }}
+
+		return fn(o)
+	})
+}
+
+// Iter returns an iterator over the {{$modelNameSingular}} records from the query, like Each.
+// An error stops the iteration and is yielded with a nil record.
+func (q {{$varNameSingular}}Query) Iter(ctx context.Context) iter.Seq2[*{{$modelNameSingular}}, error] {
+	return func(yield func(*{{$modelNameSingular}}, error) bool) {
+		err := q.Each(ctx, func(o *{{$modelNameSingular}}) error {
+			if !yield(o, nil) {
+				return errStopIteration
+			}
+			return nil
+		})
+		if err != nil && err != errStopIteration {
+			yield(nil, err)
+		}
+	}
+}
+
 // Count returns the count of all {{$modelNameSingular}} records in the query.
 func (q {{$varNameSingular}}Query) Count(ctx context.Context) (int64, error) {
 	var count int64
@@ -53,7 +82,7 @@ func (q {{$varNameSingular}}Query) Count(ctx context.Context) (int64, error) {
 	queries.SetSelect(q.Query, nil)
 	queries.SetCount(q.Query)
 
-	err := q.Query.QueryRow(ctx).Scan(&count)
+	err := q.Query.QueryRow(queries.ReadReplica(ctx, q.Query)).Scan(&count)
 	if err != nil {
 		return 0, errors.Errorf("{{.PkgName}}: failed to count {{.Model.Name}} rows: %w", err)
 	}
@@ -68,10 +97,91 @@ func (q {{$varNameSingular}}Query) Exists(ctx context.Context) (bool, error) {
 	queries.SetCount(q.Query)
 	queries.SetLimit(q.Query, 1)
 
-	err := q.Query.QueryRow(ctx).Scan(&count)
+	err := q.Query.QueryRow(queries.ReadReplica(ctx, q.Query)).Scan(&count)
 	if err != nil {
 		return false, errors.Errorf("{{.PkgName}}: failed to check if {{.Model.Name}} exists: %w", err)
 	}
 
 	return count > 0, nil
-}
\ No newline at end of file
+}
+
+// {{$modelNameSingular}}Page is a page of {{$modelNameSingular}} records returned by Paginate.
+type {{$modelNameSingular}}Page struct {
+	Items {{$modelNameSingular}}Slice
+	// Next and Prev are the cursors of the next and previous pages, empty if there are none.
+	Next string
+	Prev string
+}
+
+// Paginate returns a page of at most limit {{$modelNameSingular}} records from the query, in ascending
+// order of columns, using keyset pagination. The primary key is added to the columns to make the order
+// unique, and the columns must not be nullable. An empty cursor returns the first page, otherwise
+// it must be the Next or Prev cursor of a page returned by Paginate with the same columns.
+// The order by of the query is replaced.
+func (q {{$varNameSingular}}Query) Paginate(ctx context.Context, cursor string, limit int, columns ...{{$modelNameSingular}}Column) (*{{$modelNameSingular}}Page, error) {
+	return q.paginate(ctx, cursor, limit, false, columns)
+}
+
+// PaginateDesc is like Paginate, but in descending order of columns. Its cursors
+// can only be used with PaginateDesc.
+func (q {{$varNameSingular}}Query) PaginateDesc(ctx context.Context, cursor string, limit int, columns ...{{$modelNameSingular}}Column) (*{{$modelNameSingular}}Page, error) {
+	return q.paginate(ctx, cursor, limit, true, columns)
+}
+
+func (q {{$varNameSingular}}Query) paginate(ctx context.Context, cursor string, limit int, desc bool, columns []{{$modelNameSingular}}Column) (*{{$modelNameSingular}}Page, error) {
+	cols := queries.KeysetColumns(columnStrings(columns), {{$varNameSingular}}PrimaryKeyColumns)
+	mapping, err := queries.BindMapping({{$varNameSingular}}Type, {{$varNameSingular}}Mapping, cols)
+	if err != nil {
+		return nil, err
+	}
+
+	var before bool
+	var values []any
+	if cursor != "" {
+		value := reflect.Indirect(reflect.ValueOf(&{{$modelNameSingular}}{}))
+		before, err = queries.DecodeCursor(cursor, queries.PtrsFromMapping(value, mapping))
+		if err != nil {
+			return nil, errors.Errorf("{{.PkgName}}: failed to paginate {{.Model.Name}}: %w", err)
+		}
+		values = queries.ValuesFromMapping(value, mapping)
+	}
+
+	// The rows before the cursor are selected in reverse order, then reversed.
+	queries.SetKeyset(q.Query, "{{.Model.Name}}", cols, values, desc != before)
+	queries.SetLimit(q.Query, limit+1)
+
+	items, err := q.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	more := len(items) > limit
+	if more {
+		items = items[:limit]
+	}
+	if before {
+		slices.Reverse(items)
+	}
+
+	page := &{{$modelNameSingular}}Page{Items: items}
+	if len(items) == 0 {
+		return page, nil
+	}
+
+	if more || before {
+		last := reflect.Indirect(reflect.ValueOf(items[len(items)-1]))
+		page.Next, err = queries.EncodeCursor(false, queries.ValuesFromMapping(last, mapping))
+		if err != nil {
+			return nil, errors.Errorf("{{.PkgName}}: failed to paginate {{.Model.Name}}: %w", err)
+		}
+	}
+	if (more && before) || (!before && cursor != "") {
+		first := reflect.Indirect(reflect.ValueOf(items[0]))
+		page.Prev, err = queries.EncodeCursor(true, queries.ValuesFromMapping(first, mapping))
+		if err != nil {
+			return nil, errors.Errorf("{{.PkgName}}: failed to paginate {{.Model.Name}}: %w", err)
+		}
+	}
+
+	return page, nil
+}
@@ -0,0 +1,336 @@
+{{- $dot := . -}}
+{{- $model := .Model -}}
+{{- $modelName := .Model.Name | titleCase -}}
+{{- $modelNameCamel := .Model.Name | camelCase -}}
+
+{{ range .Model.Relationships -}}
+
+{{- $relationship := . }}
+{{- $relationshipName := .Name | titleCase}}
+{{- $foreignModel := index $dot.Schema.Models .ForeignModel }}
+{{- $foreignModelName := .ForeignModel | titleCase}}
+{{- $foreignModelNamePlural := .ForeignModel | plural | titleCase -}}
+
+{{if .IsArray}}
+{{- $localArray := index .LocalFields 0 -}}
+{{- $foreignCol := index .ForeignFields 0 -}}
+{{- $lcol := $model.FindField $localArray -}}
+{{- $fcol := $foreignModel.FindField $foreignCol }}
+// Add{{$relationshipName}} appends the keys of related to o.{{$localArray | titleCasePath}}, skipping the
+// ones already in it, updates o and appends related to o.R.{{$relationshipName}}.
+func (o *{{$modelName}}) Add{{$relationshipName}}(ctx context.Context, related ...*{{$foreignModelName}}) error {
+	ids := append(make({{goType $lcol.GoType}}, 0, len(o.{{$localArray | titleCasePath}})+len(related)), o.{{$localArray | titleCasePath}}...)
+	for _, rel := range related {
+		if !slices.Contains(ids, rel.{{$foreignCol | titleCasePath}}) {
+			ids = append(ids, rel.{{$foreignCol | titleCasePath}})
+		}
+	}
+
+	o.{{$localArray | titleCasePath}} = ids
+	if err := o.Update(ctx, {{$modelName}}Columns.{{$localArray.SQLName | titleCase}}); err != nil {
+		return err
+	}
+
+	if o.R == nil {
+		o.R = &{{$modelNameCamel}}R{}
+	}
+	o.R.{{$relationshipName}} = append(o.R.{{$relationshipName}}, related...)
+	return nil
+}
+
+// Remove{{$relationshipName}} removes the keys of related from o.{{$localArray | titleCasePath}}, updates o
+// and removes related from o.R.{{$relationshipName}}.
+func (o *{{$modelName}}) Remove{{$relationshipName}}(ctx context.Context, related ...*{{$foreignModelName}}) error {
+	ids := append(make({{goType $lcol.GoType}}, 0, len(o.{{$localArray | titleCasePath}})), o.{{$localArray | titleCasePath}}...)
+	ids = slices.DeleteFunc(ids, func(id {{goType $fcol.GoType}}) bool {
+		return slices.ContainsFunc(related, func(rel *{{$foreignModelName}}) bool {
+			return rel.{{$foreignCol | titleCasePath}} == id
+		})
+	})
+
+	o.{{$localArray | titleCasePath}} = ids
+	if err := o.Update(ctx, {{$modelName}}Columns.{{$localArray.SQLName | titleCase}}); err != nil {
+		return err
+	}
+
+	if o.R != nil {
+		o.R.{{$relationshipName}} = slices.DeleteFunc(o.R.{{$relationshipName}}, func(rel *{{$foreignModelName}}) bool {
+			return slices.Contains(related, rel)
+		})
+	}
+	return nil
+}
+
+// Set{{$relationshipName}} replaces o.{{$localArray | titleCasePath}} with the keys of related, updates o
+// and sets o.R.{{$relationshipName}} to related.
+func (o *{{$modelName}}) Set{{$relationshipName}}(ctx context.Context, related ...*{{$foreignModelName}}) error {
+	ids := make({{goType $lcol.GoType}}, 0, len(related))
+	for _, rel := range related {
+		ids = append(ids, rel.{{$foreignCol | titleCasePath}})
+	}
+
+	o.{{$localArray | titleCasePath}} = ids
+	if err := o.Update(ctx, {{$modelName}}Columns.{{$localArray.SQLName | titleCase}}); err != nil {
+		return err
+	}
+
+	if o.R == nil {
+		o.R = &{{$modelNameCamel}}R{}
+	}
+	o.R.{{$relationshipName}} = related
+	return nil
+}
+{{else if .IsJoinModel}}
+{{- $joinModel := index $dot.Schema.Models .JoinModel }}
+{{- $joinModelName := .JoinModel | titleCase}}
+{{- $joinModelNamePlural := .JoinModel | plural | titleCase}}
+{{- if .ToMany}}
+// Add{{$relationshipName}} relates o to related by inserting {{.JoinModel}} rows, and appends
+// related to o.R.{{$relationshipName}}.
+func (o *{{$modelName}}) Add{{$relationshipName}}(ctx context.Context, related ...*{{$foreignModelName}}) error {
+	err := bunny.Atomic(ctx, func(ctx context.Context) error {
+		return o.insert{{$relationshipName}}(ctx, related)
+	})
+	if err != nil {
+		return err
+	}
+
+	if o.R == nil {
+		o.R = &{{$modelNameCamel}}R{}
+	}
+	o.R.{{$relationshipName}} = append(o.R.{{$relationshipName}}, related...)
+	return nil
+}
+
+// Remove{{$relationshipName}} removes the relation between o and related by deleting their
+// {{.JoinModel}} rows, and removes related from o.R.{{$relationshipName}}.
+func (o *{{$modelName}}) Remove{{$relationshipName}}(ctx context.Context, related ...*{{$foreignModelName}}) error {
+	err := bunny.Atomic(ctx, func(ctx context.Context) error {
+		for _, rel := range related {
+			j := &{{$joinModelName}}{}
+			{{- range $i, $lc := .LocalFields}}
+			{{- $jc := index $relationship.JoinLocalFields $i }}
+			{{doAssign (printf "j.%s" ($jc | titleCasePath)) (printf "o.%s" ($lc | titleCasePath)) ($joinModel.FindField $jc) ($model.FindField $lc)}}
+			{{- end}}
+			{{- range $i, $fc := .ForeignFields}}
+			{{- $jc := index $relationship.JoinForeignFields $i }}
+			{{doAssign (printf "j.%s" ($jc | titleCasePath)) (printf "rel.%s" ($fc | titleCasePath)) ($joinModel.FindField $jc) ($foreignModel.FindField $fc)}}
+			{{- end}}
+			if err := j.Delete(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if o.R != nil {
+		o.R.{{$relationshipName}} = slices.DeleteFunc(o.R.{{$relationshipName}}, func(rel *{{$foreignModelName}}) bool {
+			return slices.Contains(related, rel)
+		})
+	}
+	return nil
+}
+
+// Set{{$relationshipName}} replaces the {{.JoinModel}} rows of o with rows relating it to related,
+// and sets o.R.{{$relationshipName}} to related.
+func (o *{{$modelName}}) Set{{$relationshipName}}(ctx context.Context, related ...*{{$foreignModelName}}) error {
+{{- else}}
+// Set{{$relationshipName}} replaces the {{.JoinModel}} row of o with a row relating it to related,
+// and sets o.R.{{$relationshipName}} to related.
+func (o *{{$modelName}}) Set{{$relationshipName}}(ctx context.Context, related *{{$foreignModelName}}) error {
+{{- end}}
+	err := bunny.Atomic(ctx, func(ctx context.Context) error {
+		err := {{$joinModelNamePlural}}(
+			qm.Where("{{whereClause $dot.LQ $dot.RQ 0 .JoinLocalFields}}"{{range .LocalFields}}, o.{{. | titleCasePath}}{{end}}),
+			{{- if .JoinWhere}}
+			{{- $schemaModel := .JoinModel | schemaModel }}
+			qm.Where("{{replaceAll .JoinWhere "$join" $schemaModel}}"),
+			{{- end}}
+		).DeleteAll(ctx)
+		if err != nil {
+			return err
+		}
+		{{if .ToMany -}}
+		return o.insert{{$relationshipName}}(ctx, related)
+		{{- else -}}
+		return o.insert{{$relationshipName}}(ctx, []*{{$foreignModelName}}{related})
+		{{- end}}
+	})
+	if err != nil {
+		return err
+	}
+
+	if o.R == nil {
+		o.R = &{{$modelNameCamel}}R{}
+	}
+	o.R.{{$relationshipName}} = related
+	return nil
+}
+
+func (o *{{$modelName}}) insert{{$relationshipName}}(ctx context.Context, related []*{{$foreignModelName}}) error {
+	for _, rel := range related {
+		j := &{{$joinModelName}}{}
+		{{- range $i, $lc := .LocalFields}}
+		{{- $jc := index $relationship.JoinLocalFields $i }}
+		{{doAssign (printf "j.%s" ($jc | titleCasePath)) (printf "o.%s" ($lc | titleCasePath)) ($joinModel.FindField $jc) ($model.FindField $lc)}}
+		{{- end}}
+		{{- range $i, $fc := .ForeignFields}}
+		{{- $jc := index $relationship.JoinForeignFields $i }}
+		{{doAssign (printf "j.%s" ($jc | titleCasePath)) (printf "rel.%s" ($fc | titleCasePath)) ($joinModel.FindField $jc) ($foreignModel.FindField $fc)}}
+		{{- end}}
+		if err := j.Insert(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+{{else if and (not .ToMany) (relationshipKeyIsLocal $model .)}}
+// Set{{$relationshipName}} relates o to related by setting the {{range $i, $lc := .LocalFields}}{{if $i}}, {{end}}{{$lc | titleCasePath}}{{end}} of o to its
+// {{range $i, $fc := .ForeignFields}}{{if $i}}, {{end}}{{$fc | titleCasePath}}{{end}}, updates o and sets o.R.{{$relationshipName}} to related.
+func (o *{{$modelName}}) Set{{$relationshipName}}(ctx context.Context, related *{{$foreignModelName}}) error {
+	{{- range $i, $lc := .LocalFields}}
+	{{- $fc := index $relationship.ForeignFields $i }}
+	{{doAssign (printf "o.%s" ($lc | titleCasePath)) (printf "related.%s" ($fc | titleCasePath)) ($model.FindField $lc) ($foreignModel.FindField $fc)}}
+	{{- end}}
+	if err := o.Update(ctx{{range .LocalFields}}, {{$modelName}}Columns.{{.SQLName | titleCase}}{{end}}); err != nil {
+		return err
+	}
+
+	if o.R == nil {
+		o.R = &{{$modelNameCamel}}R{}
+	}
+	o.R.{{$relationshipName}} = related
+	return nil
+}
+{{else}}
+{{- $nullable := fieldsNullable $foreignModel .ForeignFields }}
+{{- if .ToMany}}
+// Add{{$relationshipName}} relates o to related by setting their {{range $i, $fc := .ForeignFields}}{{if $i}}, {{end}}{{$fc | titleCasePath}}{{end}} to the
+// {{range $i, $lc := .LocalFields}}{{if $i}}, {{end}}{{$lc | titleCasePath}}{{end}} of o, updates them and appends them to o.R.{{$relationshipName}}.
+func (o *{{$modelName}}) Add{{$relationshipName}}(ctx context.Context, related ...*{{$foreignModelName}}) error {
+	err := bunny.Atomic(ctx, func(ctx context.Context) error {
+		return o.update{{$relationshipName}}(ctx, related)
+	})
+	if err != nil {
+		return err
+	}
+
+	if o.R == nil {
+		o.R = &{{$modelNameCamel}}R{}
+	}
+	o.R.{{$relationshipName}} = append(o.R.{{$relationshipName}}, related...)
+	return nil
+}
+{{- if $nullable}}
+
+// Remove{{$relationshipName}} removes the relation between o and related by setting their
+// {{range $i, $fc := .ForeignFields}}{{if $i}}, {{end}}{{$fc | titleCasePath}}{{end}} to null, updates them and removes them from o.R.{{$relationshipName}}.
+// The rows of related not related to o are left unchanged.
+func (o *{{$modelName}}) Remove{{$relationshipName}}(ctx context.Context, related ...*{{$foreignModelName}}) error {
+	err := bunny.Atomic(ctx, func(ctx context.Context) error {
+		for _, rel := range related {
+			if !({{range $i, $fc := .ForeignFields}}{{if $i}} && {{end}}{{$lc := index $relationship.LocalFields $i}}{{doCompare (printf "rel.%s" ($fc | titleCasePath)) (printf "o.%s" ($lc | titleCasePath)) ($foreignModel.FindField $fc) ($model.FindField $lc)}}{{end}}) {
+				continue
+			}
+			{{- range .ForeignFields}}
+			rel.{{. | titleCasePath}} = {{goType ($foreignModel.FindField .).GoType}}{}
+			{{- end}}
+			if err := rel.Update(ctx{{range .ForeignFields}}, {{$foreignModelName}}Columns.{{.SQLName | titleCase}}{{end}}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if o.R != nil {
+		o.R.{{$relationshipName}} = slices.DeleteFunc(o.R.{{$relationshipName}}, func(rel *{{$foreignModelName}}) bool {
+			return slices.Contains(related, rel)
+		})
+	}
+	return nil
+}
+
+// Set{{$relationshipName}} replaces the {{.ForeignModel}} rows related to o with related. The
+// {{range $i, $fc := .ForeignFields}}{{if $i}}, {{end}}{{$fc | titleCasePath}}{{end}} of the rows currently related is set to null,
+// then related are added like in Add{{$relationshipName}}. o.R.{{$relationshipName}} is set to related.
+func (o *{{$modelName}}) Set{{$relationshipName}}(ctx context.Context, related ...*{{$foreignModelName}}) error {
+	err := bunny.Atomic(ctx, func(ctx context.Context) error {
+		if err := o.clear{{$relationshipName}}(ctx); err != nil {
+			return err
+		}
+		return o.update{{$relationshipName}}(ctx, related)
+	})
+	if err != nil {
+		return err
+	}
+
+	if o.R == nil {
+		o.R = &{{$modelNameCamel}}R{}
+	}
+	o.R.{{$relationshipName}} = related
+	return nil
+}
+{{- end}}
+{{- else}}
+// Set{{$relationshipName}} relates o to related by setting its {{range $i, $fc := .ForeignFields}}{{if $i}}, {{end}}{{$fc | titleCasePath}}{{end}} to the
+// {{range $i, $lc := .LocalFields}}{{if $i}}, {{end}}{{$lc | titleCasePath}}{{end}} of o, updates it and sets o.R.{{$relationshipName}} to related.
+{{- if $nullable}}
+// The {{range $i, $fc := .ForeignFields}}{{if $i}}, {{end}}{{$fc | titleCasePath}}{{end}} of the row currently related is set to null first.
+{{- end}}
+func (o *{{$modelName}}) Set{{$relationshipName}}(ctx context.Context, related *{{$foreignModelName}}) error {
+	err := bunny.Atomic(ctx, func(ctx context.Context) error {
+		{{- if $nullable}}
+		if err := o.clear{{$relationshipName}}(ctx); err != nil {
+			return err
+		}
+		{{- end}}
+		return o.update{{$relationshipName}}(ctx, []*{{$foreignModelName}}{related})
+	})
+	if err != nil {
+		return err
+	}
+
+	if o.R == nil {
+		o.R = &{{$modelNameCamel}}R{}
+	}
+	o.R.{{$relationshipName}} = related
+	return nil
+}
+{{- end}}
+
+func (o *{{$modelName}}) update{{$relationshipName}}(ctx context.Context, related []*{{$foreignModelName}}) error {
+	for _, rel := range related {
+		{{- range $i, $fc := .ForeignFields}}
+		{{- $lc := index $relationship.LocalFields $i }}
+		{{doAssign (printf "rel.%s" ($fc | titleCasePath)) (printf "o.%s" ($lc | titleCasePath)) ($foreignModel.FindField $fc) ($model.FindField $lc)}}
+		{{- end}}
+		if err := rel.Update(ctx{{range .ForeignFields}}, {{$foreignModelName}}Columns.{{.SQLName | titleCase}}{{end}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+{{- if $nullable}}
+
+func (o *{{$modelName}}) clear{{$relationshipName}}(ctx context.Context) error {
+	return {{$foreignModelNamePlural}}(
+		qm.Where("{{whereClause $dot.LQ $dot.RQ 0 .ForeignFields}}"{{range .LocalFields}}, o.{{. | titleCasePath}}{{end}}),
+		{{- if .ForeignWhere}}
+		{{- $schemaModel := .ForeignModel | schemaModel }}
+		qm.Where("{{replaceAll .ForeignWhere "$foreign" $schemaModel}}"),
+		{{- end}}
+	).UpdateMapAll(ctx, M{
+		{{- range .ForeignFields}}
+		"{{.SQLName}}": nil,
+		{{- end}}
+	})
+}
+{{- end}}
+{{end}}
+{{end -}}
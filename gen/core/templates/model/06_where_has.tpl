@@ -0,0 +1,68 @@
+{{- $dot := . -}}
+{{- $model := .Model -}}
+{{- $modelName := .Model.Name | titleCase -}}
+{{- $modelNameCamel := .Model.Name | camelCase -}}
+{{- if .Model.Relationships}}
+// {{$modelNameCamel}}WhereHas builds EXISTS subqueries on the relationships of {{.Model.Name}}.
+type {{$modelNameCamel}}WhereHas struct {
+	op string
+}
+
+// {{$modelName}}WhereHas has mods matching the {{.Model.Name}} rows with related rows in a relationship.
+// The related rows are filtered with the mods passed to it, which must qualify their columns
+// with the model name of the related rows, like the ModelWhere clauses do, or with
+// their alias in a relationship to the same model.
+var {{$modelName}}WhereHas = {{$modelNameCamel}}WhereHas{op: "EXISTS"}
+
+// {{$modelName}}WhereDoesntHave has mods matching the {{.Model.Name}} rows without related rows in a
+// relationship, like {{$modelName}}WhereHas.
+var {{$modelName}}WhereDoesntHave = {{$modelNameCamel}}WhereHas{op: "NOT EXISTS"}
+{{- end}}
+
+{{ range .Model.Relationships -}}
+{{- $relationshipName := .Name | titleCase}}
+{{- $schemaModel := .ForeignModel | schemaModel }}
+{{- /* The inner tables named like an outer one are aliased, so the subquery isn't correlated with itself. */}}
+{{- $foreignAlias := .ForeignModel }}
+{{- $foreignRef := $schemaModel }}
+{{- if eq .ForeignModel $model.Name }}
+{{- $foreignAlias = printf "%s_1" .ForeignModel }}
+{{- $foreignRef = printf "%s%s%s" $dot.LQ $foreignAlias $dot.RQ }}
+{{- end }}
+// {{$relationshipName}} matches the rows by whether they have {{.Name}} matching mods.
+{{- if ne $foreignAlias .ForeignModel}}
+// The {{.ForeignModel}} rows of the relationship are aliased as {{$foreignAlias}} in the mods.
+{{- end}}
+func (w {{$modelNameCamel}}WhereHas) {{$relationshipName}}(mods ...qm.QueryMod) qm.QueryMod {
+	query := NewQuery(
+		qm.Select("1"),
+		qm.From("{{$schemaModel}}{{if ne $foreignAlias .ForeignModel}} AS {{$foreignRef}}{{end}}"),
+		{{- if .IsJoinModel}}
+		{{- $joinSchemaModel := .JoinModel | schemaModel }}
+		{{- $joinAlias := .JoinModel }}
+		{{- $joinRef := $joinSchemaModel }}
+		{{- if or (eq .JoinModel $model.Name) (eq .JoinModel .ForeignModel) }}
+		{{- $joinAlias = printf "%s_2" .JoinModel }}
+		{{- $joinRef = printf "%s%s%s" $dot.LQ $joinAlias $dot.RQ }}
+		{{- end }}
+		qm.InnerJoin("{{$joinSchemaModel}}{{if ne $joinAlias .JoinModel}} AS {{$joinRef}}{{end}} ON {{joinOnClause $dot.LQ $dot.RQ $joinAlias .JoinForeignFields $foreignAlias .ForeignFields}}"),
+		qm.Where("{{joinOnClause $dot.LQ $dot.RQ $joinAlias .JoinLocalFields $model.Name .LocalFields}}"),
+		{{- if .JoinWhere}}
+		qm.Where("{{replaceAll .JoinWhere "$join" $joinRef}}"),
+		{{- end}}
+		{{- else if .IsArray}}
+		{{- $localArray := index .LocalFields 0 }}
+		{{- $foreignCol := index .ForeignFields 0 }}
+		qm.Where("{{$dot.LQ}}{{$foreignAlias}}{{$dot.RQ}}.{{$dot.LQ}}{{$foreignCol.SQLName}}{{$dot.RQ}} = ANY({{$dot.LQ}}{{$model.Name}}{{$dot.RQ}}.{{$dot.LQ}}{{$localArray.SQLName}}{{$dot.RQ}})"),
+		{{- else}}
+		qm.Where("{{joinOnClause $dot.LQ $dot.RQ $foreignAlias .ForeignFields $model.Name .LocalFields}}"),
+		{{- end}}
+		{{- if .ForeignWhere}}
+		qm.Where("{{replaceAll .ForeignWhere "$foreign" $foreignRef}}"),
+		{{- end}}
+	)
+	qm.Apply(query, mods...)
+
+	return qm.Where(w.op+" ?", query)
+}
+{{end -}}
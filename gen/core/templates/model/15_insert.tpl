@@ -6,6 +6,12 @@
 // No whitelist behavior: Without a whitelist, fields are inferred by the following rules:
 // - All fields without a default value are included (i.e. name, age)
 // - All fields with a default, but non-zero are included (i.e. health = 75)
+// Fields with a default that are not inserted are set to the value generated by the database.
+{{- if .Model.ReturnAll}}
+// All the fields are set to the values of the inserted row.
+{{- end}}
+// Constraint violations are returned as bunny.UniqueViolationError, ForeignKeyViolationError,
+// NotNullViolationError or CheckViolationError.
 func (o *{{$modelNameSingular}}) Insert(ctx context.Context, whitelist ... {{$modelNameSingular}}Column) error {
 	if o == nil {
 		return errors.New("{{.PkgName}}: no {{.Model.Name}} provided for insertion")
@@ -24,14 +30,20 @@ func (o *{{$modelNameSingular}}) InsertIgnore(ctx context.Context, ignoreConflic
 
 	{{ hook . "before_insert" "o" .Model }}
 
-	var wl []string
+	value := reflect.Indirect(reflect.ValueOf(o))
+
+	var wl, ret []string
 	if len(whitelist) == 0 {
-		wl = {{$varNameSingular}}Columns
+		wl, ret = queries.InsertColumns(value, {{$varNameSingular}}Mapping, {{$varNameSingular}}Columns, {{$varNameSingular}}DefaultColumns)
 	} else {
 		wl = columnStrings(whitelist)
+		ret = strmangle.SetComplement({{$varNameSingular}}DefaultColumns, wl)
 	}
+	{{- if .Model.ReturnAll}}
+	ret = {{$varNameSingular}}Columns
+	{{- end}}
 
-	key := makeCacheKey(append(wl, ignoreConflictCondition))
+	key := makeCacheKey(wl) + "|" + makeCacheKey(ret) + "|" + ignoreConflictCondition
 	{{$varNameSingular}}InsertCacheMut.RLock()
 	cache, cached := {{$varNameSingular}}InsertCache[key]
 	{{$varNameSingular}}InsertCacheMut.RUnlock()
@@ -51,21 +63,42 @@ func (o *{{$modelNameSingular}}) InsertIgnore(ctx context.Context, ignoreConflic
         if len(ignoreConflictCondition) > 0 {
            cache.query += fmt.Sprintf(" ON CONFLICT %s DO NOTHING", ignoreConflictCondition)
         }
+
+		if len(ret) != 0 {
+			cache.returnMapping, err = queries.BindMapping({{$varNameSingular}}Type, {{$varNameSingular}}Mapping, ret)
+			if err != nil {
+				return false, err
+			}
+			cache.query += fmt.Sprintf(" RETURNING {{.LQ}}%s{{.RQ}}", strings.Join(ret, "{{.RQ}},{{.LQ}}"))
+		}
 	}
 
-	value := reflect.Indirect(reflect.ValueOf(o))
 	vals := queries.ValuesFromMapping(value, cache.valueMapping)
 
-	res, err := bunny.Exec(ctx, cache.query, vals...)
-	if err != nil {
-		return false, errors.Errorf("{{.PkgName}}: unable to insert into {{.Model.Name}}: %w", err)
-	}
+	var inserted bool
+	if len(cache.returnMapping) != 0 {
+		err = bunny.ClassifyError(bunny.QueryRow(ctx, cache.query, vals...).Scan(queries.PtrsFromMapping(value, cache.returnMapping)...))
+		if errors.Is(err, sql.ErrNoRows) {
+			// The insert was ignored because of a conflict.
+			err = nil
+		} else if err == nil {
+			inserted = true
+		}
+		if err != nil {
+			return false, errors.Errorf("{{.PkgName}}: unable to insert into {{.Model.Name}}: %w", err)
+		}
+	} else {
+		res, err := bunny.Exec(ctx, cache.query, vals...)
+		if err != nil {
+			return false, errors.Errorf("{{.PkgName}}: unable to insert into {{.Model.Name}}: %w", err)
+		}
 
-	aff, err := res.RowsAffected()
-	if err != nil {
-		return false, errors.Errorf("{{.PkgName}}: unable to get rows affected for insert into {{.Model.Name}}: %w", err)
+		aff, err := res.RowsAffected()
+		if err != nil {
+			return false, errors.Errorf("{{.PkgName}}: unable to get rows affected for insert into {{.Model.Name}}: %w", err)
+		}
+		inserted = aff != 0
 	}
-	inserted := aff != 0
 
 	if !cached {
 		{{$varNameSingular}}InsertCacheMut.Lock()
@@ -77,3 +110,69 @@ func (o *{{$modelNameSingular}}) InsertIgnore(ctx context.Context, ignoreConflic
 
 	return inserted, nil
 }
+
+// InsertAll inserts all the records in the slice, using multi-row INSERT statements
+// with as many rows as the Postgres parameter limit allows. The fields set from the
+// database are read back with RETURNING.
+// The whitelist and the fields set from the database follow the same rules as Insert.
+// The insert hooks are run for each record. Run it in bunny.Atomic if the records
+// must be inserted all or none.
+func (o {{$modelNameSingular}}Slice) InsertAll(ctx context.Context, whitelist ...{{$modelNameSingular}}Column) error {
+	values := make([]reflect.Value, len(o))
+	for i, obj := range o {
+		if obj == nil {
+			return errors.New("{{.PkgName}}: no {{.Model.Name}} provided for insertion")
+		}
+		values[i] = reflect.Indirect(reflect.ValueOf(obj))
+	}
+
+	{{ hook . "before_insert_slice" "o" .Model }}
+
+	for _, g := range queries.GroupInsertColumns(values, {{$varNameSingular}}Mapping, {{$varNameSingular}}Columns, {{$varNameSingular}}DefaultColumns, columnStrings(whitelist)) {
+		{{- if .Model.ReturnAll}}
+		g.Returning = {{$varNameSingular}}Columns
+		{{- end}}
+		if err := insertAll(ctx, "{{$schemaModel}}", {{$varNameSingular}}Type, {{$varNameSingular}}Mapping, g); err != nil {
+			return errors.Errorf("{{.PkgName}}: unable to insert all into {{.Model.Name}}: %w", err)
+		}
+	}
+
+	{{ hook . "after_insert_slice" "o" .Model }}
+
+	return nil
+}
+
+// CopyFrom inserts all the records in the slice using the COPY protocol, which is
+// faster than InsertAll for large numbers of records.
+// The whitelist follows the same rules as Insert, but the fields with a default that
+// are not inserted are not set from the database.
+// The insert hooks are run for each record.
+func (o {{$modelNameSingular}}Slice) CopyFrom(ctx context.Context, whitelist ...{{$modelNameSingular}}Column) error {
+	values := make([]reflect.Value, len(o))
+	for i, obj := range o {
+		if obj == nil {
+			return errors.New("{{.PkgName}}: no {{.Model.Name}} provided for insertion")
+		}
+		values[i] = reflect.Indirect(reflect.ValueOf(obj))
+	}
+
+	{{ hook . "before_insert_slice" "o" .Model }}
+
+	var err error
+	for _, g := range queries.GroupInsertColumns(values, {{$varNameSingular}}Mapping, {{$varNameSingular}}Columns, {{$varNameSingular}}DefaultColumns, columnStrings(whitelist)) {
+		if len(g.Columns) == 0 {
+			// COPY needs at least one column.
+			g.Returning = nil
+			err = insertAll(ctx, "{{$schemaModel}}", {{$varNameSingular}}Type, {{$varNameSingular}}Mapping, g)
+		} else {
+			err = copyFrom(ctx, "{{.Model.Name}}", {{$varNameSingular}}Type, {{$varNameSingular}}Mapping, g)
+		}
+		if err != nil {
+			return errors.Errorf("{{.PkgName}}: unable to copy into {{.Model.Name}}: %w", err)
+		}
+	}
+
+	{{ hook . "after_insert_slice" "o" .Model }}
+
+	return nil
+}
@@ -6,8 +6,14 @@
 // No whitelist behavior: Without a whitelist, fields are inferred by the following rules:
 // - All fields are inferred to start with
 // - All primary keys are subtracted from this set
+{{- if .Model.ReturnAll}}
+// All the fields are set to the values of the updated row.
+{{- else}}
 // Update does not automatically update the record in case of default values. Use .Reload()
 // to refresh the records.
+{{- end}}
+// Constraint violations are returned as bunny.UniqueViolationError, ForeignKeyViolationError,
+// NotNullViolationError or CheckViolationError.
 func (o *{{$modelNameSingular}}) Update(ctx context.Context, whitelist ... {{$modelNameSingular}}Column) error {
 	var err error
 
@@ -39,14 +45,30 @@ func (o *{{$modelNameSingular}}) Update(ctx context.Context, whitelist ... {{$mo
 		if err != nil {
 			return err
 		}
+		{{- if .Model.ReturnAll}}
+
+		cache.returnMapping, err = queries.BindMapping({{$varNameSingular}}Type, {{$varNameSingular}}Mapping, {{$varNameSingular}}Columns)
+		if err != nil {
+			return err
+		}
+		cache.query += fmt.Sprintf(" RETURNING {{.LQ}}%s{{.RQ}}", strings.Join({{$varNameSingular}}Columns, "{{.RQ}},{{.LQ}}"))
+		{{- end}}
 	}
 
-	values := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), cache.valueMapping)
+	value := reflect.Indirect(reflect.ValueOf(o))
+	values := queries.ValuesFromMapping(value, cache.valueMapping)
 
+	{{if .Model.ReturnAll -}}
+	err = bunny.ClassifyError(bunny.QueryRow(ctx, cache.query, values...).Scan(queries.PtrsFromMapping(value, cache.returnMapping)...))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return errors.Errorf("{{.PkgName}}: unable to update {{.Model.Name}} row: %w", err)
+	}
+	{{- else -}}
 	_, err = bunny.Exec(ctx, cache.query, values...)
 	if err != nil {
 		return errors.Errorf("{{.PkgName}}: unable to update {{.Model.Name}} row: %w", err)
 	}
+	{{- end}}
 
 	if !cached {
 		{{$varNameSingular}}UpdateCacheMut.Lock()
@@ -0,0 +1,107 @@
+{{- $modelNameSingular := .Model.Name | singular | titleCase -}}
+{{- $varNameSingular := .Model.Name | singular | camelCase -}}
+{{- $schemaModel := .Model.Name | schemaModel}}
+// {{$modelNameSingular}}Conflict is the target of an Upsert, a set of columns with
+// a unique constraint. The possible targets are in {{$modelNameSingular}}Conflicts.
+type {{$modelNameSingular}}Conflict struct {
+	columns []string
+}
+
+// {{$modelNameSingular}}Conflicts are the conflict targets of {{$modelNameSingular}},
+// its primary key and its uniques.
+var {{$modelNameSingular}}Conflicts = struct {
+	{{range conflictTargets .Model -}}
+	{{.Name}} {{$modelNameSingular}}Conflict
+	{{end -}}
+}{
+	{{range conflictTargets .Model -}}
+	{{.Name}}: {{$modelNameSingular}}Conflict{columns: []string{{"{"}}{{.Columns | stringMap $.StringFuncs.quoteWrap | join ", "}}{{"}"}}},
+	{{end -}}
+}
+
+// Upsert inserts the record, or updates the existing row if the insert conflicts
+// with it on the conflict target. The insert hooks are run in both cases.
+// The inserted fields follow the same rules as Insert.
+// If update is nil, all the inserted fields except the primary key and the conflict
+// target are updated. If update is empty but not nil, the existing row is left as is.
+{{- if .Model.ReturnAll}}
+// All the fields are set to the values of the inserted or updated row.
+{{- end}}
+func (o *{{$modelNameSingular}}) Upsert(ctx context.Context, conflict {{$modelNameSingular}}Conflict, update []{{$modelNameSingular}}Column, insert ...{{$modelNameSingular}}Column) error {
+	if o == nil {
+		return errors.New("{{.PkgName}}: no {{.Model.Name}} provided for upsert")
+	}
+	if len(conflict.columns) == 0 {
+		return errors.New("{{.PkgName}}: no conflict target provided for upsert of {{.Model.Name}}")
+	}
+
+	var err error
+
+	{{ hook . "before_insert" "o" .Model }}
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+
+	var wl, ret []string
+	if len(insert) == 0 {
+		wl, ret = queries.InsertColumns(value, {{$varNameSingular}}Mapping, {{$varNameSingular}}Columns, {{$varNameSingular}}DefaultColumns)
+	} else {
+		wl = columnStrings(insert)
+		ret = strmangle.SetComplement({{$varNameSingular}}DefaultColumns, wl)
+	}
+	{{- if .Model.ReturnAll}}
+	ret = {{$varNameSingular}}Columns
+	{{- end}}
+
+	var upd []string
+	if update == nil {
+		upd = strmangle.SetComplement(strmangle.SetComplement(wl, {{$varNameSingular}}PrimaryKeyColumns), conflict.columns)
+	} else {
+		upd = columnStrings(update)
+	}
+
+	key := makeCacheKey(wl) + "|" + makeCacheKey(ret) + "|" + makeCacheKey(upd) + "|" + makeCacheKey(conflict.columns)
+	{{$varNameSingular}}UpsertCacheMut.RLock()
+	cache, cached := {{$varNameSingular}}UpsertCache[key]
+	{{$varNameSingular}}UpsertCacheMut.RUnlock()
+
+	if !cached {
+		cache.valueMapping, err = queries.BindMapping({{$varNameSingular}}Type, {{$varNameSingular}}Mapping, wl)
+		if err != nil {
+			return err
+		}
+
+		if len(ret) != 0 {
+			cache.returnMapping, err = queries.BindMapping({{$varNameSingular}}Type, {{$varNameSingular}}Mapping, ret)
+			if err != nil {
+				return err
+			}
+		}
+
+		cache.query = queries.BuildUpsertQueryPostgres(dialect, "{{$schemaModel}}", len(upd) != 0, ret, upd, conflict.columns, wl)
+	}
+
+	vals := queries.ValuesFromMapping(value, cache.valueMapping)
+
+	if len(cache.returnMapping) != 0 {
+		err = bunny.ClassifyError(bunny.QueryRow(ctx, cache.query, vals...).Scan(queries.PtrsFromMapping(value, cache.returnMapping)...))
+		if errors.Is(err, sql.ErrNoRows) {
+			// The conflicting row was left as is.
+			err = nil
+		}
+	} else {
+		_, err = bunny.Exec(ctx, cache.query, vals...)
+	}
+	if err != nil {
+		return errors.Errorf("{{.PkgName}}: unable to upsert {{.Model.Name}}: %w", err)
+	}
+
+	if !cached {
+		{{$varNameSingular}}UpsertCacheMut.Lock()
+		{{$varNameSingular}}UpsertCache[key] = cache
+		{{$varNameSingular}}UpsertCacheMut.Unlock()
+	}
+
+	{{ hook . "after_insert" "o" .Model }}
+
+	return nil
+}
@@ -1,5 +1,11 @@
 import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
 	"github.com/sqlbunny/errors"
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
     "github.com/sqlbunny/sqlbunny/runtime/strmangle"
 	"github.com/sqlbunny/sqlbunny/runtime/queries"
 )
@@ -7,14 +13,19 @@ import (
 // M type is for providing fields and field values to UpdateAll.
 type M map[string]any
 
+// errStopIteration is returned to Each to stop it when the loop over Iter is broken.
+var errStopIteration = errors.New("stop iteration")
+
 type insertCache struct {
-	query        string
-	valueMapping []queries.MappedField
+	query         string
+	valueMapping  []queries.MappedField
+	returnMapping []queries.MappedField
 }
 
 type updateCache struct {
-	query        string
-	valueMapping []queries.MappedField
+	query         string
+	valueMapping  []queries.MappedField
+	returnMapping []queries.MappedField
 }
 
 func makeCacheKey(wl []string) string {
@@ -37,3 +48,81 @@ func columnStrings[T ~string](cols []T) []string {
 	}
 	return result
 }
+
+// insertAll inserts the values of g into table with multi-row INSERT statements,
+// as many rows per statement as the parameter limit allows. The returned columns
+// are scanned into the values in order, as Postgres returns the rows of a multi-row
+// INSERT ... VALUES in the order of the VALUES.
+func insertAll(ctx context.Context, table string, typ reflect.Type, mapping map[string]queries.MappedField, g *queries.InsertGroup) error {
+	valueMapping, err := queries.BindMapping(typ, mapping, g.Columns)
+	if err != nil {
+		return err
+	}
+	var returnMapping []queries.MappedField
+	if len(g.Returning) != 0 {
+		returnMapping, err = queries.BindMapping(typ, mapping, g.Returning)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, batch := range g.Batches() {
+		var args []any
+		for _, value := range batch {
+			args = append(args, queries.ValuesFromMapping(value, valueMapping)...)
+		}
+		query := queries.BuildInsertAllQuery(dialect, table, g.Columns, g.Returning, len(batch))
+		if len(g.Returning) == 0 {
+			if _, err := bunny.Exec(ctx, query, args...); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := insertReturning(ctx, query, args, batch, returnMapping); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertReturning runs the insert query of batch, scanning the returned rows
+// into the values of batch.
+func insertReturning(ctx context.Context, query string, args []any, batch []reflect.Value, returnMapping []queries.MappedField) error {
+	rows, err := bunny.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		if n == len(batch) {
+			return errors.Errorf("insert returned more than the %d inserted rows", len(batch))
+		}
+		if err := rows.Scan(queries.PtrsFromMapping(batch[n], returnMapping)...); err != nil {
+			return errors.WithStack(bunny.ClassifyError(err))
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return errors.WithStack(bunny.ClassifyError(err))
+	}
+	if n != len(batch) {
+		return errors.Errorf("insert returned %d of the %d inserted rows", n, len(batch))
+	}
+	return nil
+}
+
+// copyFrom inserts the values of g into table using the COPY protocol.
+func copyFrom(ctx context.Context, table string, typ reflect.Type, mapping map[string]queries.MappedField, g *queries.InsertGroup) error {
+	valueMapping, err := queries.BindMapping(typ, mapping, g.Columns)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]any, len(g.Values))
+	for i, value := range g.Values {
+		rows[i] = queries.ValuesFromMapping(value, valueMapping)
+	}
+	return bunny.CopyFrom(ctx, table, g.Columns, rows)
+}
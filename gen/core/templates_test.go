@@ -0,0 +1,344 @@
+package core
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/sqlbunny/sqlbunny/gen"
+	"github.com/sqlbunny/sqlbunny/runtime/queries"
+)
+
+// executeModelTemplate renders the model template file for a model named
+// "author" defined with items.
+func executeModelTemplate(t *testing.T, file string, items ...ModelItem) string {
+	t.Helper()
+	return executeTemplate(t, file, "author", Model("author", items...))
+}
+
+// executeTemplate renders the model template file for model, in a schema
+// defined by items, with the string and int64 types.
+func executeTemplate(t *testing.T, file string, model string, items ...gen.ConfigItem) string {
+	t.Helper()
+
+	gen.Config = &gen.ConfigStruct{
+		Dialect: queries.Dialect{
+			LQ:                '"',
+			RQ:                '"',
+			IndexPlaceholders: true,
+		},
+		ModelsPackageName: "models",
+	}
+
+	s, err := buildSchema(append([]gen.ConfigItem{
+		Type("string", BaseType{
+			Go:       "string",
+			GoNull:   "github.com/sqlbunny/sqlbunny/types/null.String",
+			Postgres: SQLType{Type: "text", ZeroValue: "''"},
+		}),
+		Type("int64", BaseType{
+			Go:       "int64",
+			GoNull:   "github.com/sqlbunny/sqlbunny/types/null.Int64",
+			Postgres: SQLType{Type: "bigint", ZeroValue: "0"},
+		}),
+	}, items...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen.Config.Schema = s
+
+	tpl, err := template.New("").Funcs(gen.TemplateFunctions).ParseFiles(templatesModelDirectory + "/" + file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := gen.BaseTemplateData()
+	data["Model"] = s.Models[model]
+	var buf bytes.Buffer
+	if err := tpl.ExecuteTemplate(&buf, file, data); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestUpdateReturnAll(t *testing.T) {
+	fields := []ModelItem{
+		Field("id", "string", PrimaryKey),
+		Field("name", "string"),
+		Field("created_at", "string", Default("'x'")),
+	}
+
+	out := executeModelTemplate(t, "16_update.tpl", fields...)
+	if strings.Contains(out, "RETURNING") || !strings.Contains(out, "bunny.Exec(ctx, cache.query") {
+		t.Errorf("expected Update to run without RETURNING:\n%s", out)
+	}
+
+	out = executeModelTemplate(t, "16_update.tpl", append(fields, ReturnAll)...)
+	if !strings.Contains(out, "RETURNING") || !strings.Contains(out, "cache.returnMapping") {
+		t.Errorf("expected Update to read back the row with RETURNING:\n%s", out)
+	}
+}
+
+func TestInsertReturnAll(t *testing.T) {
+	fields := []ModelItem{
+		Field("id", "string", PrimaryKey),
+		Field("name", "string"),
+		Field("created_at", "string", Default("'x'")),
+	}
+
+	out := executeModelTemplate(t, "15_insert.tpl", fields...)
+	if strings.Contains(out, "ret = authorColumns") {
+		t.Errorf("expected Insert to read back only the default columns:\n%s", out)
+	}
+
+	out = executeModelTemplate(t, "15_insert.tpl", append(fields, ReturnAll)...)
+	if !strings.Contains(out, "ret = authorColumns") {
+		t.Errorf("expected Insert to read back all the columns:\n%s", out)
+	}
+}
+
+func TestWhereFields(t *testing.T) {
+	out := executeTemplate(t, "02_where.tpl", "book",
+		Type("money", Struct(
+			Field("amount", "int64"),
+			Field("currency", "string"),
+		)),
+		Model("book",
+			Field("id", "string", PrimaryKey),
+			Field("title", "string", Null),
+			Field("price", "money", Null),
+			Field("list_price", "money"),
+		),
+	)
+
+	// The null types are imported with generated names.
+	for _, want := range []string{
+		`ID qm.WhereField\[string\]\n`,
+		`Title qm.WhereNullField\[\w+\.String\]\n`,
+		`Price qm.WhereNullStruct\n`,
+		`PriceAmount qm.WhereNullField\[int64\]\n`,
+		`ListPriceCurrency qm.WhereField\[string\]\n`,
+		`ID: qm.NewWhereField\[string\]\("\\"book\\"\.\\"id\\""\),`,
+		`Title: qm.NewWhereNullField\[\w+\.String\]\("\\"book\\"\.\\"title\\""\),`,
+		`Price: qm.NewWhereNullStruct\("\\"book\\"\.\\"price\\""\),`,
+		`PriceAmount: qm.NewWhereNullField\[int64\]\("\\"book\\"\.\\"price__amount\\""\),`,
+		`ListPriceCurrency: qm.NewWhereField\[string\]\("\\"book\\"\.\\"list_price__currency\\""\),`,
+	} {
+		if !regexp.MustCompile(want).MatchString(out) {
+			t.Errorf("expected %s in:\n%s", want, out)
+		}
+	}
+}
+
+func TestWhereHas(t *testing.T) {
+	out := executeTemplate(t, "06_where_has.tpl", "author",
+		Type("string_array", BaseType{
+			Go:       "github.com/lib/pq.StringArray",
+			Postgres: SQLType{Type: "text[]", ZeroValue: "'{}'"},
+		}),
+		Model("author",
+			Field("id", "string", PrimaryKey),
+			Field("mentor_id", "string", Null, ForeignKey("author")),
+			Field("book_ids", "string_array"),
+			Field("co_author_ids", "string_array"),
+			Relationship("books", DirectRelationship{ForeignModel: "book", ToMany: true, LocalFields: []string{"id"}, ForeignFields: []string{"author_id"}, ForeignWhere: "$foreign.deleted IS NOT TRUE"}),
+			Relationship("mentor", DirectRelationship{ForeignModel: "author", LocalFields: []string{"mentor_id"}, ForeignFields: []string{"id"}, ForeignWhere: "$foreign.active"}),
+			Relationship("tags", JoinRelationship{ForeignModel: "tag", JoinModel: "author_tag", ToMany: true, LocalFields: []string{"id"}, JoinLocalFields: []string{"author_id"}, JoinForeignFields: []string{"tag_id"}, ForeignFields: []string{"id"}, JoinWhere: "$join.visible"}),
+			Relationship("followers", JoinRelationship{ForeignModel: "author", JoinModel: "follow", ToMany: true, LocalFields: []string{"id"}, JoinLocalFields: []string{"followee_id"}, JoinForeignFields: []string{"follower_id"}, ForeignFields: []string{"id"}, JoinWhere: "$join.accepted", ForeignWhere: "$foreign.active"}),
+			Relationship("featured_books", ArrayRelationship{ForeignModel: "book", LocalArrayField: "book_ids", ForeignField: "id"}),
+			Relationship("co_authors", ArrayRelationship{ForeignModel: "author", LocalArrayField: "co_author_ids", ForeignField: "id", ForeignWhere: "$foreign.active"}),
+		),
+		Model("book",
+			Field("id", "string", PrimaryKey),
+			Field("author_id", "string", ForeignKey("author")),
+		),
+		Model("tag",
+			Field("id", "string", PrimaryKey),
+		),
+		Model("author_tag",
+			Field("author_id", "string", ForeignKey("author")),
+			Field("tag_id", "string", ForeignKey("tag")),
+			PrimaryKey("author_id", "tag_id"),
+		),
+		Model("follow",
+			Field("follower_id", "string", ForeignKey("author")),
+			Field("followee_id", "string", ForeignKey("author")),
+			PrimaryKey("follower_id", "followee_id"),
+		),
+	)
+
+	for _, want := range []string{
+		// Direct
+		`func (w authorWhereHas) Books(mods ...qm.QueryMod) qm.QueryMod {
+	query := NewQuery(
+		qm.Select("1"),
+		qm.From("\"book\""),
+		qm.Where("\"book\".\"author_id\"=\"author\".\"id\""),
+		qm.Where("\"book\".deleted IS NOT TRUE"),
+	)`,
+		// Direct, self-referential
+		`func (w authorWhereHas) Mentor(mods ...qm.QueryMod) qm.QueryMod {
+	query := NewQuery(
+		qm.Select("1"),
+		qm.From("\"author\" AS \"author_1\""),
+		qm.Where("\"author_1\".\"id\"=\"author\".\"mentor_id\""),
+		qm.Where("\"author_1\".active"),
+	)`,
+		// Join
+		`func (w authorWhereHas) Tags(mods ...qm.QueryMod) qm.QueryMod {
+	query := NewQuery(
+		qm.Select("1"),
+		qm.From("\"tag\""),
+		qm.InnerJoin("\"author_tag\" ON \"author_tag\".\"tag_id\"=\"tag\".\"id\""),
+		qm.Where("\"author_tag\".\"author_id\"=\"author\".\"id\""),
+		qm.Where("\"author_tag\".visible"),
+	)`,
+		// Join, self-referential
+		`func (w authorWhereHas) Followers(mods ...qm.QueryMod) qm.QueryMod {
+	query := NewQuery(
+		qm.Select("1"),
+		qm.From("\"author\" AS \"author_1\""),
+		qm.InnerJoin("\"follow\" ON \"follow\".\"follower_id\"=\"author_1\".\"id\""),
+		qm.Where("\"follow\".\"followee_id\"=\"author\".\"id\""),
+		qm.Where("\"follow\".accepted"),
+		qm.Where("\"author_1\".active"),
+	)`,
+		// Array
+		`func (w authorWhereHas) FeaturedBooks(mods ...qm.QueryMod) qm.QueryMod {
+	query := NewQuery(
+		qm.Select("1"),
+		qm.From("\"book\""),
+		qm.Where("\"book\".\"id\" = ANY(\"author\".\"book_ids\")"),
+	)`,
+		// Array, self-referential
+		`func (w authorWhereHas) CoAuthors(mods ...qm.QueryMod) qm.QueryMod {
+	query := NewQuery(
+		qm.Select("1"),
+		qm.From("\"author\" AS \"author_1\""),
+		qm.Where("\"author_1\".\"id\" = ANY(\"author\".\"co_author_ids\")"),
+		qm.Where("\"author_1\".active"),
+	)`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected:\n%s\nin:\n%s", want, out)
+		}
+	}
+}
+
+func TestRelationshipSet(t *testing.T) {
+	out := executeTemplate(t, "05_relationship_set.tpl", "author",
+		Type("string_array", BaseType{
+			Go:       "github.com/lib/pq.StringArray",
+			Postgres: SQLType{Type: "text[]", ZeroValue: "'{}'"},
+		}),
+		Model("author",
+			Field("id", "string", PrimaryKey),
+			Field("mentor_id", "string", Null, ForeignKey("author")),
+			Field("book_ids", "string_array"),
+			Relationship("featured_books", ArrayRelationship{ForeignModel: "book", LocalArrayField: "book_ids", ForeignField: "id"}),
+			Relationship("tags", JoinRelationship{ForeignModel: "tag", JoinModel: "author_tag", ToMany: true, LocalFields: []string{"id"}, JoinLocalFields: []string{"author_id"}, JoinForeignFields: []string{"tag_id"}, ForeignFields: []string{"id"}, JoinWhere: "$join.visible"}),
+			Relationship("main_tag", JoinRelationship{ForeignModel: "tag", JoinModel: "author_main_tag", LocalFields: []string{"id"}, JoinLocalFields: []string{"author_id"}, JoinForeignFields: []string{"tag_id"}, ForeignFields: []string{"id"}}),
+			Relationship("visible_reviews", DirectRelationship{ForeignModel: "review", ToMany: true, LocalFields: []string{"id"}, ForeignFields: []string{"author_id"}, ForeignWhere: "$foreign.visible"}),
+		),
+		Model("book",
+			Field("id", "string", PrimaryKey),
+			Field("author_id", "string", ForeignKey("author")),
+		),
+		Model("review",
+			Field("id", "string", PrimaryKey),
+			Field("author_id", "string", Null, ForeignKey("author")),
+		),
+		Model("portrait",
+			Field("id", "string", PrimaryKey),
+			Field("author_id", "string", Null, Unique, ForeignKey("author")),
+		),
+		Model("tag",
+			Field("id", "string", PrimaryKey),
+		),
+		Model("author_tag",
+			Field("author_id", "string", ForeignKey("author")),
+			Field("tag_id", "string", ForeignKey("tag")),
+			PrimaryKey("author_id", "tag_id"),
+		),
+		Model("author_main_tag",
+			Field("author_id", "string", PrimaryKey, ForeignKey("author")),
+			Field("tag_id", "string", ForeignKey("tag")),
+		),
+		Model("biography",
+			Field("id", "string", PrimaryKey),
+			Field("author_id", "string", Unique, ForeignKey("author")),
+		),
+	)
+
+	for _, want := range []string{
+		// Array
+		`func (o *Author) AddFeaturedBooks(ctx context.Context, related ...*Book) error {`,
+		`	ids = slices.DeleteFunc(ids, func(id string) bool {
+		return slices.ContainsFunc(related, func(rel *Book) bool {
+			return rel.ID == id
+		})
+	})
+
+	o.BookIds = ids
+	if err := o.Update(ctx, AuthorColumns.BookIds); err != nil {`,
+		`func (o *Author) SetFeaturedBooks(ctx context.Context, related ...*Book) error {`,
+		// Join, to-many
+		`func (o *Author) AddTags(ctx context.Context, related ...*Tag) error {`,
+		`			j := &AuthorTag{}
+			j.AuthorID = o.ID
+			j.TagID = rel.ID
+			if err := j.Delete(ctx); err != nil {`,
+		`		err := AuthorTags(
+			qm.Where("\"author_id\"=?", o.ID),
+			qm.Where("\"author_tag\".visible"),
+		).DeleteAll(ctx)`,
+		`		return o.insertTags(ctx, related)`,
+		// Join, to-one
+		`func (o *Author) SetMainTag(ctx context.Context, related *Tag) error {`,
+		`		return o.insertMainTag(ctx, []*Tag{related})`,
+		// Local key
+		`func (o *Author) SetMentor(ctx context.Context, related *Author) error {`,
+		`	if err := o.Update(ctx, AuthorColumns.MentorID); err != nil {`,
+		// Nullable foreign key, to-many
+		`			if !(rel.AuthorID.Valid && rel.AuthorID.String == o.ID) {
+				continue
+			}`,
+		`func (o *Author) clearVisibleReviews(ctx context.Context) error {
+	return Reviews(
+		qm.Where("\"author_id\"=?", o.ID),
+		qm.Where("\"review\".visible"),
+	).UpdateMapAll(ctx, M{
+		"author_id": nil,
+	})
+}`,
+		`func (o *Author) clearReviews(ctx context.Context) error {
+	return Reviews(
+		qm.Where("\"author_id\"=?", o.ID),
+	).UpdateMapAll(ctx, M{`,
+		// Nullable foreign key, to-one
+		`		if err := o.clearPortrait(ctx); err != nil {
+			return err
+		}
+		return o.updatePortrait(ctx, []*Portrait{related})`,
+		// Foreign key, to-many and to-one
+		`func (o *Author) AddBooks(ctx context.Context, related ...*Book) error {`,
+		`		rel.AuthorID = o.ID
+		if err := rel.Update(ctx, BookColumns.AuthorID); err != nil {`,
+		`	err := bunny.Atomic(ctx, func(ctx context.Context) error {
+		return o.updateBiography(ctx, []*Biography{related})
+	})`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected:\n%s\nin:\n%s", want, out)
+		}
+	}
+
+	// The rows of a foreign key that isn't nullable can't be removed from the relationship.
+	for _, unwanted := range []string{"RemoveBooks", "SetBooks", "clearBooks", "clearBiography"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("unexpected %s in:\n%s", unwanted, out)
+		}
+	}
+}
@@ -24,6 +24,7 @@ func buildSchema(items []gen.ConfigItem) (*schema.Schema, error) {
 		checkIndexes(ctx, m)
 		checkUniques(ctx, m)
 		checkForeignKeys(ctx, m)
+		checkChecks(ctx, m)
 	}
 
 	// TODO disallow double underscore.
@@ -106,6 +107,10 @@ func checkDuplicateFields(ctx *gen.Context, m *schema.Model) {
 			ctx.AddError("Model '%s' field '%s' is defined multiple times.", m.Name, f.Name)
 		}
 		seen[f.Name] = struct{}{}
+
+		if f.Default != "" && f.IsStruct() {
+			ctx.AddError("Model '%s' field '%s' is a struct, it can't have a default.", m.Name, f.Name)
+		}
 	}
 }
 
@@ -180,6 +185,13 @@ func checkForeignKeys(ctx *gen.Context, m *schema.Model) {
 				ctx.AddError("Model '%s' foreign key '%s': local field '%s' does not exist", m.Name, desc, p.DotName())
 			}
 		}
+		if f.OnDelete == SetNull || f.OnUpdate == SetNull {
+			for _, p := range f.LocalFields {
+				if !isPathNullable(m, p) {
+					ctx.AddError("Model '%s' foreign key '%s': action SET NULL needs nullable field '%s'", m.Name, desc, p.DotName())
+				}
+			}
+		}
 
 		m2, ok := ctx.Schema.Models[f.ForeignModel]
 		if !ok {
@@ -221,3 +233,28 @@ func checkForeignKeys(ctx *gen.Context, m *schema.Model) {
 		seen[desc] = struct{}{}
 	}
 }
+
+func checkChecks(ctx *gen.Context, m *schema.Model) {
+	seen := make(map[string]struct{})
+	for _, c := range m.Checks {
+		parseIdentifier(ctx, c.Name)
+		if _, ok := seen[c.Name]; ok {
+			ctx.AddError("Model '%s' check '%s' is defined multiple times.", m.Name, c.Name)
+		}
+		seen[c.Name] = struct{}{}
+
+		if strings.TrimSpace(c.Expr) == "" {
+			ctx.AddError("Model '%s' check '%s' has an empty expression", m.Name, c.Name)
+		}
+	}
+}
+
+// isPathNullable returns whether the field at p, or any struct containing it, is nullable.
+func isPathNullable(m *schema.Model, p schema.Path) bool {
+	for i := 1; i <= len(p); i++ {
+		if f := m.FindField(p[:i]); f != nil && f.Nullable {
+			return true
+		}
+	}
+	return false
+}
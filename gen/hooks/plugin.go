@@ -20,13 +20,16 @@ func (*Plugin) ConfigItem(ctx *gen.Context) {}
 func (p *Plugin) BunnyPlugin() {
 	gen.OnHook("after_delete_slice", p.hook(gen.MustLoadTemplate(templatesPackage, "templates/after_delete_slice.tpl")))
 	gen.OnHook("after_delete", p.hook(gen.MustLoadTemplate(templatesPackage, "templates/after_delete.tpl")))
+	gen.OnHook("after_insert_slice", p.hook(gen.MustLoadTemplate(templatesPackage, "templates/after_insert_slice.tpl")))
 	gen.OnHook("after_insert", p.hook(gen.MustLoadTemplate(templatesPackage, "templates/after_insert.tpl")))
 	gen.OnHook("after_select_slice", p.hook(gen.MustLoadTemplate(templatesPackage, "templates/after_select_slice.tpl")))
 	gen.OnHook("after_select_slice_noreturn", p.hook(gen.MustLoadTemplate(templatesPackage, "templates/after_select_slice_noreturn.tpl")))
+	gen.OnHook("after_select_noreturn", p.hook(gen.MustLoadTemplate(templatesPackage, "templates/after_select_noreturn.tpl")))
 	gen.OnHook("after_select", p.hook(gen.MustLoadTemplate(templatesPackage, "templates/after_select.tpl")))
 	gen.OnHook("after_update", p.hook(gen.MustLoadTemplate(templatesPackage, "templates/after_update.tpl")))
 	gen.OnHook("before_delete_slice", p.hook(gen.MustLoadTemplate(templatesPackage, "templates/before_delete_slice.tpl")))
 	gen.OnHook("before_delete", p.hook(gen.MustLoadTemplate(templatesPackage, "templates/before_delete.tpl")))
+	gen.OnHook("before_insert_slice", p.hook(gen.MustLoadTemplate(templatesPackage, "templates/before_insert_slice.tpl")))
 	gen.OnHook("before_insert", p.hook(gen.MustLoadTemplate(templatesPackage, "templates/before_insert.tpl")))
 	gen.OnHook("before_update", p.hook(gen.MustLoadTemplate(templatesPackage, "templates/before_update.tpl")))
 	gen.OnHook("model", p.modelHook(gen.MustLoadTemplate(templatesPackage, "templates/model.tpl")))
@@ -0,0 +1,9 @@
+{{- $varNameSingular := .Model.Name | singular | camelCase -}}
+
+	if len({{$varNameSingular}}AfterInsertHooks) != 0 {
+		for _, obj := range {{.Var}} {
+			if err := obj.doAfterInsertHooks(ctx); err != nil {
+				return err
+			}
+		}
+	}
@@ -0,0 +1,3 @@
+	if err := {{.Var}}.doAfterSelectHooks(ctx); err != nil {
+		return err
+	}
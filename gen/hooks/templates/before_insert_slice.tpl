@@ -0,0 +1,9 @@
+{{- $varNameSingular := .Model.Name | singular | camelCase -}}
+
+	if len({{$varNameSingular}}BeforeInsertHooks) != 0 {
+		for _, obj := range {{.Var}} {
+			if err := obj.doBeforeInsertHooks(ctx); err != nil {
+				return err
+			}
+		}
+	}
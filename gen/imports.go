@@ -7,7 +7,7 @@ import (
 )
 
 var (
-	imports     map[string]string
+	imports     = make(map[string]string)
 	importCount int
 )
 
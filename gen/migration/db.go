@@ -0,0 +1,46 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"os"
+
+	_ "github.com/lib/pq"
+	"github.com/spf13/cobra"
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+)
+
+const databaseURLEnv = "DATABASE_URL"
+
+func addDBFlag(cmd *cobra.Command) {
+	cmd.Flags().String("db", "", "Postgres connection URL. Defaults to the "+databaseURLEnv+" environment variable.")
+}
+
+// dbURL returns the database given by the --db flag or the environment, if any.
+func dbURL(cmd *cobra.Command) string {
+	url, _ := cmd.Flags().GetString("db")
+	if url == "" {
+		url = os.Getenv(databaseURLEnv)
+	}
+	return url
+}
+
+// dbContext connects to the database given by the --db flag and returns a context
+// bunny queries can run on.
+func dbContext(cmd *cobra.Command) context.Context {
+	url := dbURL(cmd)
+	if url == "" {
+		log.Fatalf("No database given. Use the --db flag or set %s.", databaseURLEnv)
+	}
+
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		log.Fatalf("Error opening database: %v", err)
+	}
+	if err := db.Ping(); err != nil {
+		log.Fatalf("Error connecting to database: %v", err)
+	}
+
+	return bunny.ContextWithDB(context.Background(), db)
+}
@@ -0,0 +1,327 @@
+package migration
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/sqlbunny/sqlbunny/gen"
+	"github.com/sqlbunny/sqlbunny/runtime/migration"
+	"github.com/sqlbunny/sqlbunny/schema"
+	"github.com/sqlbunny/sqlbunny/sqlschema/diff"
+	"github.com/sqlbunny/sqlbunny/sqlschema/introspect"
+	sqlschema "github.com/sqlbunny/sqlbunny/sqlschema/schema"
+)
+
+func (p *Plugin) addImportCommand() {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Generate model definitions and an initial migration from an existing database.",
+		Long: `Generate model definitions and an initial migration from an existing database.
+
+The model definitions are written as a Go file declaring a list of config items,
+which must be added to the items passed to Run. The initial migration creates the
+current database schema, and is marked as applied in the database.`,
+		Run: p.cmdImport,
+	}
+	addDBFlag(cmd)
+	cmd.Flags().String("out", "imported_models.go", "File to write the model definitions to.")
+	cmd.Flags().String("package", "main", "Package name of the model definitions file.")
+	cmd.Flags().String("var", "importedModels", "Name of the variable holding the model definitions.")
+	gen.AddCommand(cmd)
+}
+
+func (p *Plugin) cmdImport(cmd *cobra.Command, args []string) {
+	p.ensureStore()
+	if len(p.Store.Migrations) != 0 {
+		log.Fatal("The migration store already has migrations, import only works on projects without migrations.")
+	}
+
+	out, _ := cmd.Flags().GetString("out")
+	pkg, _ := cmd.Flags().GetString("package")
+	varName, _ := cmd.Flags().GetString("var")
+
+	ctx := dbContext(cmd)
+	d, err := introspect.Introspect(ctx, introspect.Options{
+		Schemas:       []string{""},
+		ExcludeTables: []string{"migrations"},
+	})
+	if err != nil {
+		log.Fatalf("Error reading database schema: %v", err)
+	}
+
+	var buf bytes.Buffer
+	writeImport(&buf, pkg, varName, d.Schemas[""], newTypeMapper(gen.Config.Schema))
+
+	dir, file := filepath.Split(out)
+	gen.WriteFile(dir, file, buf.Bytes())
+
+	m := &migration.Migration{
+		Operations: diff.Diff(newDB(), d),
+	}
+	m.Name = p.genName(m)
+	p.writeMigration(m)
+
+	if err := migration.MarkApplied(ctx, m.Name); err != nil {
+		log.Fatalf("Error marking migration '%s' as applied: %v", m.Name, err)
+	}
+
+	log.Printf("Model definitions written to %s, initial migration '%s' marked as applied.", out, m.Name)
+	log.Printf("Add %s to the config items passed to Run to use them.", varName)
+}
+
+// writeImport writes the file declaring the variable varName holding the
+// model definitions of the tables of s.
+func writeImport(buf *bytes.Buffer, pkg string, varName string, s *sqlschema.Schema, tm *typeMapper) {
+	gen.WritePackageName(buf, pkg)
+	buf.WriteString("import (\n")
+	buf.WriteString("    \"github.com/sqlbunny/sqlbunny/gen\"\n")
+	// core is only used by the model definitions.
+	if s != nil && len(s.Tables) != 0 {
+		buf.WriteString("    \"github.com/sqlbunny/sqlbunny/gen/core\"\n")
+	}
+	buf.WriteString(")\n\n")
+	fmt.Fprintf(buf, "// %s were generated from the database schema by 'sqlbunny import'.\n", varName)
+	fmt.Fprintf(buf, "var %s = []gen.ConfigItem{\n", varName)
+	writeModels(buf, s, tm)
+	buf.WriteString("}\n")
+}
+
+// typeMapper finds the configured type for a Postgres column type.
+type typeMapper struct {
+	types map[string][]schema.BaseType
+}
+
+func newTypeMapper(s *schema.Schema) *typeMapper {
+	tm := &typeMapper{
+		types: make(map[string][]schema.BaseType),
+	}
+	if s == nil {
+		return tm
+	}
+	for _, t := range s.Types {
+		switch t := t.(type) {
+		case *schema.BaseTypeNullable, *schema.BaseTypeNotNullable:
+			bt := t.(schema.BaseType)
+			sqlType := sqlschema.NormalizeType(bt.SQLType().Type)
+			tm.types[sqlType] = append(tm.types[sqlType], bt)
+		}
+	}
+	for _, ts := range tm.types {
+		sort.Slice(ts, func(i, j int) bool {
+			return ts[i].GetName() < ts[j].GetName()
+		})
+	}
+	return tm
+}
+
+// typeFor returns the type for sqlType. Nullable columns need a type that
+// has a nullable Go type.
+func (tm *typeMapper) typeFor(sqlType string, nullable bool) (schema.BaseType, bool) {
+	ts := tm.types[sqlschema.NormalizeType(sqlType)]
+	if !nullable {
+		if len(ts) == 0 {
+			return nil, false
+		}
+		return ts[0], true
+	}
+	for _, t := range ts {
+		if _, ok := t.(schema.NullableType); ok {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+func writeModels(buf *bytes.Buffer, s *sqlschema.Schema, tm *typeMapper) {
+	if s == nil {
+		return
+	}
+	for _, name := range sortedKeys(s.Tables) {
+		writeModel(buf, s, name, s.Tables[name], tm)
+	}
+}
+
+func writeModel(buf *bytes.Buffer, s *sqlschema.Schema, name string, t *sqlschema.Table, tm *typeMapper) {
+	// Items of single-column keys are written on the field.
+	fieldItems := make(map[string][]string)
+	var modelItems []string
+
+	if t.PrimaryKey != nil {
+		if len(t.PrimaryKey.Columns) == 1 {
+			c := t.PrimaryKey.Columns[0]
+			fieldItems[c] = append(fieldItems[c], "core.PrimaryKey")
+		} else {
+			modelItems = append(modelItems, fmt.Sprintf("core.PrimaryKey(%s)", quoteAll(t.PrimaryKey.Columns)))
+		}
+	}
+
+	for _, uname := range sortedKeys(t.Uniques) {
+		u := t.Uniques[uname]
+		if len(u.Columns) == 1 {
+			c := u.Columns[0]
+			fieldItems[c] = append(fieldItems[c], "core.Unique")
+		} else {
+			modelItems = append(modelItems, fmt.Sprintf("core.Unique(%s)", quoteAll(u.Columns)))
+		}
+	}
+
+	for _, iname := range sortedKeys(t.Indexes) {
+		idx := t.Indexes[iname]
+		if len(idx.Columns) == 1 && idx.Method == "" && idx.Where == "" {
+			c := idx.Columns[0]
+			fieldItems[c] = append(fieldItems[c], "core.Index")
+			continue
+		}
+		item := fmt.Sprintf("core.Index(%s)", quoteAll(idx.Columns))
+		if idx.Method != "" {
+			item += fmt.Sprintf(".Method(%s)", strconv.Quote(idx.Method))
+		}
+		if idx.Where != "" {
+			item += fmt.Sprintf(".Where(%s)", strconv.Quote(idx.Where))
+		}
+		modelItems = append(modelItems, item)
+	}
+
+	for _, fkname := range sortedKeys(t.ForeignKeys) {
+		fk := t.ForeignKeys[fkname]
+		if fk.ForeignSchema != "" {
+			modelItems = append(modelItems, fmt.Sprintf("// TODO: foreign key %s references %s.%s, models can only reference tables in the public schema.", fkname, fk.ForeignSchema, fk.ForeignTable))
+			continue
+		}
+		if ft, ok := s.Tables[fk.ForeignTable]; !ok || ft.PrimaryKey == nil || !equalStrings(ft.PrimaryKey.Columns, fk.ForeignColumns) {
+			modelItems = append(modelItems, fmt.Sprintf("// TODO: foreign key %s references columns (%s) of %s, which are not its primary key.", fkname, strings.Join(fk.ForeignColumns, ", "), fk.ForeignTable))
+			continue
+		}
+		if len(fk.LocalColumns) == 1 {
+			c := fk.LocalColumns[0]
+			fieldItems[c] = append(fieldItems[c], fmt.Sprintf("core.ForeignKey(%s)", strconv.Quote(fk.ForeignTable))+foreignKeyOptions(fk))
+		} else {
+			modelItems = append(modelItems, fmt.Sprintf("core.ModelForeignKey(%s, %s)", strconv.Quote(fk.ForeignTable), quoteAll(fk.LocalColumns))+foreignKeyOptions(fk))
+		}
+	}
+
+	for _, cname := range sortedKeys(t.Checks) {
+		c := t.Checks[cname]
+		item := fmt.Sprintf("core.Check(%s, %s)", strconv.Quote(checkName(name, cname)), strconv.Quote(c.Expr))
+		if c.NotValid {
+			item += ".NotValid()"
+		}
+		modelItems = append(modelItems, item)
+	}
+
+	fmt.Fprintf(buf, "core.Model(%s,\n", strconv.Quote(name))
+	for _, cname := range columnOrder(t) {
+		c := t.Columns[cname]
+		typeName, zeroValue := c.Type, ""
+		if t, ok := tm.typeFor(c.Type, c.Nullable); ok {
+			typeName, zeroValue = t.GetName(), t.SQLType().ZeroValue
+		} else if c.Nullable {
+			fmt.Fprintf(buf, "// TODO: no type with a nullable Go type is defined for Postgres type %s.\n", c.Type)
+		} else {
+			fmt.Fprintf(buf, "// TODO: no type is defined for Postgres type %s.\n", c.Type)
+		}
+
+		items := []string{strconv.Quote(cname), strconv.Quote(typeName)}
+		if c.Nullable {
+			items = append(items, "core.Null")
+		}
+		if c.Default != "" && (c.Nullable || c.Default != zeroValue) {
+			items = append(items, fmt.Sprintf("core.Default(%s)", strconv.Quote(c.Default)))
+		}
+		items = append(items, fieldItems[cname]...)
+		fmt.Fprintf(buf, "core.Field(%s),\n", strings.Join(items, ", "))
+	}
+	for _, item := range modelItems {
+		if strings.HasPrefix(item, "//") {
+			fmt.Fprintf(buf, "%s\n", item)
+		} else {
+			fmt.Fprintf(buf, "%s,\n", item)
+		}
+	}
+	buf.WriteString("),\n")
+}
+
+// checkName returns the name of the check in the model, removing the model
+// name prefix and suffix of constraints created by sqlbunny.
+func checkName(model, constraint string) string {
+	prefix, suffix := model+"___", "___check"
+	if strings.HasPrefix(constraint, prefix) && strings.HasSuffix(constraint, suffix) && len(constraint) > len(prefix)+len(suffix) {
+		return constraint[len(prefix) : len(constraint)-len(suffix)]
+	}
+	return constraint
+}
+
+var foreignKeyActionNames = map[sqlschema.ForeignKeyAction]string{
+	sqlschema.Restrict:   "core.Restrict",
+	sqlschema.Cascade:    "core.Cascade",
+	sqlschema.SetNull:    "core.SetNull",
+	sqlschema.SetDefault: "core.SetDefault",
+}
+
+// foreignKeyOptions returns the method calls setting the actions of fk.
+func foreignKeyOptions(fk *sqlschema.ForeignKey) string {
+	var res string
+	if fk.OnDelete != sqlschema.NoAction {
+		res += fmt.Sprintf(".OnDelete(%s)", foreignKeyActionNames[fk.OnDelete])
+	}
+	if fk.OnUpdate != sqlschema.NoAction {
+		res += fmt.Sprintf(".OnUpdate(%s)", foreignKeyActionNames[fk.OnUpdate])
+	}
+	if fk.Deferrable {
+		res += ".Deferrable()"
+	}
+	return res
+}
+
+// columnOrder returns the primary key columns first, then the rest in table order.
+func columnOrder(t *sqlschema.Table) []string {
+	var res []string
+	seen := make(map[string]struct{})
+	if t.PrimaryKey != nil {
+		for _, c := range t.PrimaryKey.Columns {
+			res = append(res, c)
+			seen[c] = struct{}{}
+		}
+	}
+	for _, c := range t.ColumnNames() {
+		if _, ok := seen[c]; !ok {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+func quoteAll(ss []string) string {
+	res := make([]string, len(ss))
+	for i, s := range ss {
+		res[i] = strconv.Quote(s)
+	}
+	return strings.Join(res, ", ")
+}
+
+func equalStrings(x, y []string) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	res := make([]string, 0, len(m))
+	for k := range m {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
@@ -0,0 +1,273 @@
+package migration
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sqlbunny/sqlbunny/schema"
+	sqlschema "github.com/sqlbunny/sqlbunny/sqlschema/schema"
+)
+
+func testTypeMapper() *typeMapper {
+	s := schema.New()
+	s.Types["string"] = &schema.BaseTypeNullable{
+		Name:     "string",
+		Go:       schema.GoType{Name: "string"},
+		GoNull:   schema.GoType{Pkg: "github.com/sqlbunny/sqlbunny/types/null", Name: "String"},
+		Postgres: schema.SQLType{Type: "text", ZeroValue: "''"},
+	}
+	s.Types["int64"] = &schema.BaseTypeNullable{
+		Name:     "int64",
+		Go:       schema.GoType{Name: "int64"},
+		GoNull:   schema.GoType{Pkg: "github.com/sqlbunny/sqlbunny/types/null", Name: "Int64"},
+		Postgres: schema.SQLType{Type: "bigint", ZeroValue: "0"},
+	}
+	s.Types["point"] = &schema.BaseTypeNotNullable{
+		Name:     "point",
+		Go:       schema.GoType{Pkg: "github.com/sqlbunny/geo", Name: "Point"},
+		Postgres: schema.SQLType{Type: "geometry(Point)", ZeroValue: "'POINT(0 0)'"},
+	}
+	return newTypeMapper(s)
+}
+
+func column(typ string, position int) *sqlschema.Column {
+	return &sqlschema.Column{Type: typ, Position: position}
+}
+
+func nullColumn(typ string, position int) *sqlschema.Column {
+	return &sqlschema.Column{Type: typ, Nullable: true, Position: position}
+}
+
+func TestWriteModel(t *testing.T) {
+	tests := []struct {
+		name   string
+		tables map[string]*sqlschema.Table
+		expect string
+	}{
+		{
+			name: "single column keys",
+			tables: map[string]*sqlschema.Table{
+				"author": {
+					Columns: map[string]*sqlschema.Column{
+						"id":    column("text", 0),
+						"email": column("text", 1),
+					},
+					PrimaryKey: &sqlschema.PrimaryKey{Columns: []string{"id"}},
+					Uniques:    map[string]*sqlschema.Unique{"author___email___key": {Columns: []string{"email"}}},
+				},
+				"book": {
+					Columns: map[string]*sqlschema.Column{
+						"title":     column("text", 2),
+						"id":        column("text", 0),
+						"author_id": nullColumn("text", 1),
+						"seq":       {Type: "bigint", Default: "0", Position: 3},
+						"count":     {Type: "bigint", Default: "1", Position: 4},
+					},
+					PrimaryKey: &sqlschema.PrimaryKey{Columns: []string{"id"}},
+					Indexes:    map[string]*sqlschema.Index{"book___title___idx": {Columns: []string{"title"}}},
+					ForeignKeys: map[string]*sqlschema.ForeignKey{
+						"book___author_id___fkey": {
+							LocalColumns:   []string{"author_id"},
+							ForeignTable:   "author",
+							ForeignColumns: []string{"id"},
+							OnDelete:       sqlschema.SetNull,
+							OnUpdate:       sqlschema.Cascade,
+							Deferrable:     true,
+						},
+					},
+					Checks: map[string]*sqlschema.Check{
+						"book___positive___check": {Expr: "count > 0"},
+						"legacy_check":            {Expr: "seq >= 0", NotValid: true},
+					},
+				},
+			},
+			expect: `core.Model("author",
+core.Field("id", "string", core.PrimaryKey),
+core.Field("email", "string", core.Unique),
+),
+core.Model("book",
+core.Field("id", "string", core.PrimaryKey),
+core.Field("author_id", "string", core.Null, core.ForeignKey("author").OnDelete(core.SetNull).OnUpdate(core.Cascade).Deferrable()),
+core.Field("title", "string", core.Index),
+core.Field("seq", "int64"),
+core.Field("count", "int64", core.Default("1")),
+core.Check("positive", "count > 0"),
+core.Check("legacy_check", "seq >= 0").NotValid(),
+),
+`,
+		},
+		{
+			name: "composite keys",
+			tables: map[string]*sqlschema.Table{
+				"edition": {
+					Columns: map[string]*sqlschema.Column{
+						"book_id": column("text", 0),
+						"number":  column("bigint", 1),
+					},
+					PrimaryKey: &sqlschema.PrimaryKey{Columns: []string{"book_id", "number"}},
+				},
+				"sale": {
+					Columns: map[string]*sqlschema.Column{
+						"id":      column("text", 0),
+						"book_id": column("text", 1),
+						"number":  column("bigint", 2),
+						"shop":    column("text", 3),
+					},
+					PrimaryKey: &sqlschema.PrimaryKey{Columns: []string{"id"}},
+					Uniques:    map[string]*sqlschema.Unique{"sale___book_id__number__shop___key": {Columns: []string{"book_id", "number", "shop"}}},
+					Indexes: map[string]*sqlschema.Index{
+						"sale___shop___idx": {Columns: []string{"shop"}, Method: "hash", Where: "shop <> ''"},
+					},
+					ForeignKeys: map[string]*sqlschema.ForeignKey{
+						"sale___book_id__number___fkey": {
+							LocalColumns:   []string{"book_id", "number"},
+							ForeignTable:   "edition",
+							ForeignColumns: []string{"book_id", "number"},
+							OnDelete:       sqlschema.Restrict,
+						},
+						"sale___shop___fkey": {
+							LocalColumns:   []string{"shop"},
+							ForeignTable:   "edition",
+							ForeignColumns: []string{"book_id"},
+						},
+					},
+				},
+			},
+			expect: `core.Model("edition",
+core.Field("book_id", "string"),
+core.Field("number", "int64"),
+core.PrimaryKey("book_id", "number"),
+),
+core.Model("sale",
+core.Field("id", "string", core.PrimaryKey),
+core.Field("book_id", "string"),
+core.Field("number", "int64"),
+core.Field("shop", "string"),
+core.Unique("book_id", "number", "shop"),
+core.Index("shop").Method("hash").Where("shop <> ''"),
+core.ModelForeignKey("edition", "book_id", "number").OnDelete(core.Restrict),
+// TODO: foreign key sale___shop___fkey references columns (book_id) of edition, which are not its primary key.
+),
+`,
+		},
+		{
+			name: "unknown and not nullable types",
+			tables: map[string]*sqlschema.Table{
+				"place": {
+					Columns: map[string]*sqlschema.Column{
+						"id":       column("text", 0),
+						"location": column("geometry(Point)", 1),
+						"previous": nullColumn("geometry(Point)", 2),
+						"data":     column("xml", 3),
+					},
+					PrimaryKey: &sqlschema.PrimaryKey{Columns: []string{"id"}},
+				},
+			},
+			expect: `core.Model("place",
+core.Field("id", "string", core.PrimaryKey),
+core.Field("location", "point"),
+// TODO: no type with a nullable Go type is defined for Postgres type geometry(Point).
+core.Field("previous", "geometry(Point)", core.Null),
+// TODO: no type is defined for Postgres type xml.
+core.Field("data", "xml"),
+),
+`,
+		},
+	}
+
+	tm := testTypeMapper()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &sqlschema.Schema{Tables: test.tables}
+			var buf bytes.Buffer
+			writeModels(&buf, s, tm)
+			if got := buf.String(); got != test.expect {
+				t.Errorf("bad models:\n%s\nwant:\n%s", got, test.expect)
+			}
+		})
+	}
+}
+
+func TestWriteImportEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	writeImport(&buf, "main", "importedModels", sqlschema.NewSchema(), testTypeMapper())
+
+	f, err := parser.ParseFile(token.NewFileSet(), "imported_models.go", buf.Bytes(), parser.ImportsOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, imp := range f.Imports {
+		if strings.Contains(imp.Path.Value, "gen/core") {
+			t.Errorf("core is imported without models:\n%s", buf.String())
+		}
+	}
+}
+
+func TestTypeFor(t *testing.T) {
+	tm := testTypeMapper()
+
+	tests := []struct {
+		sqlType  string
+		nullable bool
+		expect   string
+	}{
+		{"text", false, "string"},
+		{"text", true, "string"},
+		{"int8", false, "int64"},
+		{"geometry(Point)", false, "point"},
+		{"geometry(Point)", true, ""},
+		{"xml", false, ""},
+	}
+	for _, test := range tests {
+		var name string
+		if typ, ok := tm.typeFor(test.sqlType, test.nullable); ok {
+			name = typ.GetName()
+		}
+		if name != test.expect {
+			t.Errorf("typeFor(%q, %v) = %q, want %q", test.sqlType, test.nullable, name, test.expect)
+		}
+	}
+}
+
+func TestCheckName(t *testing.T) {
+	tests := []struct {
+		constraint string
+		expect     string
+	}{
+		{"book___positive___check", "positive"},
+		{"book______check", "book______check"},
+		{"author___positive___check", "author___positive___check"},
+		{"positive", "positive"},
+	}
+	for _, test := range tests {
+		if got := checkName("book", test.constraint); got != test.expect {
+			t.Errorf("checkName(%q) = %q, want %q", test.constraint, got, test.expect)
+		}
+	}
+}
+
+func TestColumnOrder(t *testing.T) {
+	table := &sqlschema.Table{
+		Columns: map[string]*sqlschema.Column{
+			"a":       column("text", 0),
+			"b":       column("text", 1),
+			"book_id": column("text", 2),
+			"number":  column("bigint", 3),
+		},
+		PrimaryKey: &sqlschema.PrimaryKey{Columns: []string{"number", "book_id"}},
+	}
+	expect := []string{"number", "book_id", "a", "b"}
+	if got := columnOrder(table); !reflect.DeepEqual(got, expect) {
+		t.Errorf("bad column order: %v", got)
+	}
+
+	table.PrimaryKey = nil
+	expect = []string{"a", "b", "book_id", "number"}
+	if got := columnOrder(table); !reflect.DeepEqual(got, expect) {
+		t.Errorf("bad column order without primary key: %v", got)
+	}
+}
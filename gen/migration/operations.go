@@ -6,10 +6,5 @@ import (
 )
 
 func ApplyMigration(m *migration.Migration, d *schema.Database) error {
-	for _, o := range m.Operations {
-		if err := o.Apply(d); err != nil {
-			return err
-		}
-	}
-	return nil
+	return m.Apply(d)
 }
@@ -1,8 +1,10 @@
 package migration
 
 import (
+	"bufio"
 	"bytes"
-	"crypto/rand"
+	"context"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"log"
@@ -10,12 +12,16 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/sanity-io/litter"
 	"github.com/spf13/cobra"
 	"github.com/sqlbunny/sqlbunny/gen"
 	"github.com/sqlbunny/sqlbunny/runtime/migration"
 	"github.com/sqlbunny/sqlbunny/sqlschema/diff"
+	"github.com/sqlbunny/sqlbunny/sqlschema/introspect"
+	"github.com/sqlbunny/sqlbunny/sqlschema/operations"
 	"github.com/sqlbunny/sqlbunny/sqlschema/schema"
 )
 
@@ -42,14 +48,19 @@ func (p *Plugin) BunnyPlugin() {
 	}
 	gen.AddCommand(cmd)
 
-	cmd.AddCommand(&cobra.Command{
+	genCmd := &cobra.Command{
 		Use: "gen",
 		Run: p.cmdGen,
-	})
-	cmd.AddCommand(&cobra.Command{
+	}
+	genCmd.Flags().Bool("detect-renames", false, "Ask whether dropped columns were renamed to added columns of the same type.")
+	cmd.AddCommand(genCmd)
+	checkCmd := &cobra.Command{
 		Use: "check",
 		Run: p.cmdCheck,
-	})
+	}
+	// If a database is given, check also that its schema matches the applied migrations.
+	addDBFlag(checkCmd)
+	cmd.AddCommand(checkCmd)
 	cmd.AddCommand(&cobra.Command{
 		Use: "merge",
 		Run: p.cmdMerge,
@@ -58,6 +69,25 @@ func (p *Plugin) BunnyPlugin() {
 		Use: "gensql",
 		Run: p.cmdGenSQL,
 	})
+
+	rollbackCmd := &cobra.Command{
+		Use:   "rollback [target]",
+		Short: "Roll back a migration and all the migrations depending on it. Defaults to the latest one.",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   p.cmdRollback,
+	}
+	addDBFlag(rollbackCmd)
+	cmd.AddCommand(rollbackCmd)
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "List the applied, pending and unknown migrations of a database.",
+		Run:   p.cmdStatus,
+	}
+	addDBFlag(statusCmd)
+	cmd.AddCommand(statusCmd)
+
+	p.addImportCommand()
 }
 
 func (p *Plugin) cmdCheck(cmd *cobra.Command, args []string) {
@@ -73,6 +103,68 @@ func (p *Plugin) cmdCheck(cmd *cobra.Command, args []string) {
 	if len(ops) != 0 {
 		log.Fatal("Migrations are not up to date with the defined models. You need to run 'migration gen'.")
 	}
+
+	if dbURL(cmd) != "" {
+		p.checkDB(dbContext(cmd))
+	}
+}
+
+func (p *Plugin) checkDB(ctx context.Context) {
+	status, err := p.Store.Status(ctx)
+	if err != nil {
+		log.Fatalf("Error reading migration status: %v", err)
+	}
+
+	applied := make(map[string]struct{})
+	for _, st := range status {
+		switch st.State {
+		case migration.Applied:
+			applied[st.Name] = struct{}{}
+		case migration.Unknown:
+			log.Fatalf("Migration '%s' is applied in the database, but is not in the migration store", st.Name)
+		}
+	}
+
+	expected, err := p.Store.Replay(applied)
+	if err != nil {
+		log.Fatalf("Error replaying migrations: %v", err)
+	}
+	actual, err := introspect.Introspect(ctx, introspect.Options{
+		ExcludeTables: []string{"migrations"},
+	})
+	if err != nil {
+		log.Fatalf("Error reading database schema: %v", err)
+	}
+
+	ops := diff.Diff(actual, expected)
+	if len(ops) != 0 {
+		log.Println("Operations needed to make the database match the applied migrations:")
+		for _, op := range ops {
+			log.Println(op.GetSQL())
+		}
+		log.Fatal("The database schema doesn't match the applied migrations.")
+	}
+}
+
+func (p *Plugin) cmdStatus(cmd *cobra.Command, args []string) {
+	if p.Store == nil {
+		log.Fatal("migrate.Plugin.Store is not set.")
+	}
+
+	status, err := p.Store.Status(dbContext(cmd))
+	if err != nil {
+		log.Fatalf("Error reading migration status: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, st := range status {
+		var at string
+		if !st.AppliedAt.IsZero() {
+			at = st.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", st.Name, st.State, at)
+	}
+	w.Flush()
 }
 
 func (p *Plugin) cmdMerge(cmd *cobra.Command, args []string) {
@@ -88,9 +180,9 @@ func (p *Plugin) cmdMerge(cmd *cobra.Command, args []string) {
 	}
 
 	m := &migration.Migration{
-		Name:         p.genName(),
 		Dependencies: heads,
 	}
+	m.Name = p.genName(m)
 	p.writeMigration(m)
 }
 
@@ -100,38 +192,98 @@ func (p *Plugin) cmdGen(cmd *cobra.Command, args []string) {
 	s1 := newDB()
 	head := p.applyAll(s1)
 	s2 := gen.Config.Schema.SQLSchema()
-	ops := diff.Diff(s1, s2)
+
+	var opts diff.Options
+	opts.TableRenames, opts.ColumnRenames = gen.Config.Schema.SQLRenames()
+	if detect, _ := cmd.Flags().GetBool("detect-renames"); detect {
+		opts.ConfirmRename = confirmRename
+	}
+	ops := diff.DiffWithOptions(s1, s2, opts)
 	if len(ops) == 0 {
 		log.Fatal("No model changes found, doing nothing.")
 	}
 
+	checkReversible(s1, ops)
+
 	var deps []string
 	if head != "" {
 		deps = []string{head}
 	}
 
 	m := &migration.Migration{
-		Name:         p.genName(),
 		Dependencies: deps,
 		Operations:   ops,
 	}
+	m.Name = p.genName(m)
 	p.writeMigration(m)
 }
 
-func (p *Plugin) genName() string {
+var stdin = bufio.NewReader(os.Stdin)
+
+// confirmRename asks on the terminal whether r is a rename.
+func confirmRename(r schema.ColumnRename) bool {
+	fmt.Printf("Was column '%s' of table '%s' renamed to '%s'? [y/N] ", r.OldName, r.TableName, r.NewName)
+	answer, _ := stdin.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// checkReversible warns about the operations that can't be rolled back.
+// d is the database state before ops are applied. It is modified.
+func checkReversible(d *schema.Database, ops []operations.Operation) {
+	for _, op := range ops {
+		if _, err := op.Reverse(d); err != nil {
+			log.Printf("Warning: migration can't be rolled back: %v", err)
+		}
+		if err := op.Apply(d); err != nil {
+			log.Fatalf("Error applying generated operation %T: %v", op, err)
+		}
+	}
+}
+
+func (p *Plugin) cmdRollback(cmd *cobra.Command, args []string) {
+	if p.Store == nil {
+		log.Fatal("migrate.Plugin.Store is not set.")
+	}
+
+	var target string
+	if len(args) != 0 {
+		target = args[0]
+	} else {
+		heads := p.Store.FindHeads()
+		if len(heads) == 0 {
+			log.Fatal("No migrations found, nothing to roll back.")
+		}
+		if len(heads) != 1 {
+			log.Fatal("Found multiple migration heads, you must specify which migration to roll back")
+		}
+		target = heads[0]
+	}
+
+	ctx := dbContext(cmd)
+	if err := p.Store.Rollback(ctx, target); err != nil {
+		log.Fatalf("Error rolling back migration '%s': %v", target, err)
+	}
+	log.Printf("Rolled back migration '%s'.", target)
+}
+
+// genName returns the name for the new migration m. The name is the next
+// migration number and a hash of the contents of m, so generating the same
+// migration twice gives the same name.
+func (p *Plugin) genName(m *migration.Migration) string {
 	n := 0
-	for m := range p.Store.Migrations {
-		i := strings.IndexFunc(m, func(r rune) bool {
+	for name := range p.Store.Migrations {
+		i := strings.IndexFunc(name, func(r rune) bool {
 			return r < '0' || r > '9'
 		})
 		if i == -1 {
-			i = len(m)
+			i = len(name)
 		}
 		if i == 0 {
 			continue
 		}
 
-		j, err := strconv.Atoi(m[:i])
+		j, err := strconv.Atoi(name[:i])
 		if err != nil {
 			panic(err) // This should never happen
 		}
@@ -142,10 +294,9 @@ func (p *Plugin) genName() string {
 	}
 	n++
 
-	var b [3]byte
-	_, _ = rand.Read(b[:])
+	h := sha256.Sum256([]byte(litter.Options{}.Sdump(m.Dependencies, m.Operations)))
 
-	return fmt.Sprintf("%05d_%s", n, hex.EncodeToString(b[:]))
+	return fmt.Sprintf("%05d_%s", n, hex.EncodeToString(h[:3]))
 }
 
 func (p *Plugin) ensureStore() {
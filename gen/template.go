@@ -158,9 +158,12 @@ var TemplateFunctions = template.FuncMap{
 		}
 		return res
 	},
-	"modelColumns":      modelColumns,
-	"modelPKColumns":    modelPKColumns,
-	"modelNonPKColumns": modelNonPKColumns,
+	"modelColumns":        modelColumns,
+	"modelPKColumns":      modelPKColumns,
+	"modelNonPKColumns":   modelNonPKColumns,
+	"modelDefaultColumns": modelDefaultColumns,
+	"conflictTargets":     conflictTargets,
+	"modelWhereFields":    modelWhereFields,
 
 	"quotes": func(s string) string {
 		d := Config.Dialect
@@ -194,16 +197,160 @@ var TemplateFunctions = template.FuncMap{
 		f := ca.Type.(schema.NullableType).GoTypeNullField()
 		return a + ".Valid && " + a + "." + f + " == " + b
 	},
+	"doAssign": func(a, b string, ca, cb *schema.Field) string {
+		if ca.Nullable == cb.Nullable {
+			return a + " = " + b
+		}
+
+		if cb.Nullable {
+			f := cb.Type.(schema.NullableType).GoTypeNullField()
+			return a + " = " + b + "." + f
+		}
+
+		t := ca.Type.(schema.NullableType)
+		return a + " = " + templateGoType(t.GoTypeNull()) + "{" + t.GoTypeNullField() + ": " + b + ", Valid: true}"
+	},
+	"relationshipKeyIsLocal": relationshipKeyIsLocal,
+	"fieldsNullable":         fieldsNullable,
+}
+
+// relationshipKeyIsLocal returns whether the local fields of the direct
+// relationship r of m hold the key, that is they reference the foreign fields.
+// Otherwise the foreign fields reference the local ones.
+func relationshipKeyIsLocal(m *schema.Model, r *schema.Relationship) bool {
+	for _, fk := range m.ForeignKeys {
+		if fk.ForeignModel == r.ForeignModel && pathsEqual(fk.LocalFields, r.LocalFields) && pathsEqual(fk.ForeignFields, r.ForeignFields) {
+			return true
+		}
+	}
+	if r.ToMany {
+		return false
+	}
+	return m.PrimaryKey == nil || !pathsEqual(m.PrimaryKey.Fields, r.LocalFields)
+}
+
+// fieldsNullable returns whether all the fields of m at paths are nullable.
+func fieldsNullable(m *schema.Model, paths []schema.Path) bool {
+	for _, p := range paths {
+		if f := m.FindField(p); f == nil || !f.Nullable {
+			return false
+		}
+	}
+	return true
+}
+
+func pathsEqual(a, b []schema.Path) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equals(b[i]) {
+			return false
+		}
+	}
+	return true
 }
 
 func modelColumns(m *schema.Model) []string {
+	return m.Table.ColumnNames()
+}
+
+// modelDefaultColumns returns the columns of the fields with a Default.
+func modelDefaultColumns(m *schema.Model) []string {
 	var res []string
-	for name := range m.Table.Columns {
-		res = append(res, name)
+	var walk func(fields []*schema.Field, prefix schema.Path)
+	walk = func(fields []*schema.Field, prefix schema.Path) {
+		for _, f := range fields {
+			path := append(append(schema.Path{}, prefix...), f.Name)
+			if s, ok := f.Type.(*schema.Struct); ok {
+				walk(s.Fields, path)
+			} else if f.Default != "" {
+				res = append(res, path.SQLName())
+			}
+		}
+	}
+	walk(m.Fields, nil)
+	return res
+}
+
+// whereField is a column of a model with typed where clauses.
+type whereField struct {
+	Column   string
+	GoType   schema.GoType
+	Nullable bool
+	// NullStruct is set for the column storing whether a nullable struct is null.
+	NullStruct bool
+}
+
+// modelWhereFields returns the columns of m, in the same order as
+// modelColumns, with the Go types of their fields.
+func modelWhereFields(m *schema.Model) []whereField {
+	byColumn := make(map[string]whereField)
+	var walk func(fields []*schema.Field, prefix schema.Path, nullable bool)
+	walk = func(fields []*schema.Field, prefix schema.Path, nullable bool) {
+		for _, f := range fields {
+			path := append(append(schema.Path{}, prefix...), f.Name)
+			if s, ok := f.Type.(*schema.Struct); ok {
+				walk(s.Fields, path, nullable || f.Nullable)
+				if f.Nullable {
+					byColumn[path.SQLName()] = whereField{Column: path.SQLName(), NullStruct: true}
+				}
+			} else {
+				byColumn[path.SQLName()] = whereField{
+					Column:   path.SQLName(),
+					GoType:   f.GoType(),
+					Nullable: nullable || f.Nullable,
+				}
+			}
+		}
+	}
+	walk(m.Fields, nil, false)
+
+	var res []whereField
+	for _, c := range modelColumns(m) {
+		res = append(res, byColumn[c])
 	}
 	return res
 }
 
+// conflictTarget is a set of columns with a unique constraint, usable as an
+// ON CONFLICT target.
+type conflictTarget struct {
+	Name    string
+	Columns []string
+}
+
+// conflictTargets returns the primary key and the uniques of m as conflict
+// targets. Uniques on the primary key columns are left out. The target names
+// join the title cased fields with "_", which title casing removes from the
+// field names, so unique(a_b) and unique(a, b) are AB and A_B.
+func conflictTargets(m *schema.Model) ([]conflictTarget, error) {
+	pk := modelPKColumns(m)
+	res := []conflictTarget{{Name: "PrimaryKey", Columns: pk}}
+	seen := map[string]struct{}{"PrimaryKey": {}}
+	for _, u := range m.Uniques {
+		t := conflictTarget{}
+		for i, p := range u.Fields {
+			if i != 0 {
+				t.Name += "_"
+			}
+			for _, n := range p {
+				t.Name += strmangle.TitleCase(n)
+			}
+			t.Columns = append(t.Columns, p.SQLName())
+		}
+		if len(strmangle.SetComplement(pk, t.Columns)) == 0 && len(pk) == len(t.Columns) {
+			continue
+		}
+		if _, ok := seen[t.Name]; ok {
+			return nil, fmt.Errorf("model '%s' has multiple conflict targets named '%s'", m.Name, t.Name)
+		}
+		seen[t.Name] = struct{}{}
+		res = append(res, t)
+	}
+	return res, nil
+}
+
 func modelPKColumns(m *schema.Model) []string {
 	var res []string
 	for _, path := range m.PrimaryKey.Fields {
@@ -0,0 +1,40 @@
+package gen
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sqlbunny/sqlbunny/schema"
+)
+
+func TestConflictTargets(t *testing.T) {
+	m := &schema.Model{
+		Name:       "book",
+		PrimaryKey: &schema.PrimaryKey{Fields: []schema.Path{{"id"}}},
+		Uniques: []*schema.Unique{
+			{Fields: []schema.Path{{"id"}}},
+			{Fields: []schema.Path{{"a_b"}}},
+			{Fields: []schema.Path{{"a"}, {"b"}}},
+			{Fields: []schema.Path{{"price", "currency"}, {"title"}}},
+		},
+	}
+
+	targets, err := conflictTargets(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []conflictTarget{
+		{Name: "PrimaryKey", Columns: []string{"id"}},
+		{Name: "AB", Columns: []string{"a_b"}},
+		{Name: "A_B", Columns: []string{"a", "b"}},
+		{Name: "PriceCurrency_Title", Columns: []string{"price__currency", "title"}},
+	}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf("bad conflict targets: %#v", targets)
+	}
+
+	m.Uniques = append(m.Uniques, &schema.Unique{Fields: []schema.Path{{"price_currency"}, {"title"}}})
+	if _, err := conflictTargets(m); err == nil {
+		t.Error("expected an error for the conflict targets with the same name")
+	}
+}
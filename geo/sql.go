@@ -7,12 +7,17 @@ import (
 )
 
 func scan(value interface{}, g geom) error {
-	b, ok := value.([]byte)
-	if !ok {
-		return errors.New("EWKB scan: value is not byte slice")
+	var s string
+	switch v := value.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return errors.New("EWKB scan: value is not byte slice or string")
 	}
 
-	data, err := hex.DecodeString(string(b))
+	data, err := hex.DecodeString(s)
 	if err != nil {
 		return err
 	}
@@ -4,16 +4,24 @@ go 1.23
 
 require (
 	github.com/davecgh/go-spew v1.1.1
+	github.com/jackc/pgx/v5 v5.7.1
 	github.com/lib/pq v1.2.0
 	github.com/sanity-io/litter v1.2.0
 	github.com/spf13/cobra v0.0.5
 	github.com/sqlbunny/errors v0.0.0-20190927201458-cf9913986328
 	github.com/volatiletech/inflect v0.0.0-20170731032912-e7201282ae8d
-	golang.org/x/tools v0.0.0-20190802220118-1d1727260058
+	golang.org/x/tools v0.26.0 // pgx v5.7.1 needs x/text v0.18.0, which needs >= v0.21.1; versions before v0.26.0 fail to build in internal/tokeninternal with recent Go
 	gopkg.in/DATA-DOG/go-sqlmock.v2 v2.0.0-20180914054222-c19298f520d0
 )
 
 require (
 	github.com/inconshreveable/mousetrap v1.0.0 // indirect
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20240606120523-5a60cdf6a761 // indirect
+	github.com/jackc/puddle/v2 v2.2.2 // indirect
 	github.com/spf13/pflag v1.0.3 // indirect
+	golang.org/x/crypto v0.27.0 // indirect
+	golang.org/x/mod v0.21.0 // indirect
+	golang.org/x/sync v0.8.0 // indirect
+	golang.org/x/text v0.18.0 // indirect
 )
@@ -0,0 +1,50 @@
+package bunny
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CopyFrom inserts rows into the columns of table using the COPY protocol,
+// which is much faster than INSERT for large numbers of rows.
+//
+// COPY needs a transaction. If ctx is not in one, CopyFrom runs in its own.
+func CopyFrom(ctx context.Context, table string, columns []string, rows [][]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	tx, ok := DBFromContext(ctx).(*txNode)
+	if !ok {
+		return Atomic(ctx, func(ctx context.Context) error {
+			return CopyFrom(ctx, table, columns, rows)
+		})
+	}
+	if tx.child != nil {
+		panic("Transaction has a subtransaction active, can't run statements in it.")
+	}
+
+	begin := time.Now()
+	err := ClassifyError(currentDriver.CopyFrom(ctx, tx.conn, tx.dbTx, table, columns, rows))
+	logger.LogQuery(ctx, QueryLogInfo{
+		Query:    copyQuery(table, columns),
+		Duration: time.Since(begin),
+		Err:      err,
+	})
+	return err
+}
+
+// copyQuery returns the COPY statement of CopyFrom, for logging.
+func copyQuery(table string, columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = quoteIdentifier(c)
+	}
+	return fmt.Sprintf("COPY %s (%s) FROM STDIN", quoteIdentifier(table), strings.Join(quoted, ", "))
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
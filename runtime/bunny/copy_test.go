@@ -0,0 +1,47 @@
+package bunny
+
+import (
+	"context"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+func TestCopyFrom(t *testing.T) {
+	ctx, mock, _ := setupTest(t)
+
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare(`COPY "book" \("id", "title"\) FROM STDIN`)
+	prep.ExpectExec().WithArgs("1", "a").WillReturnResult(sqlmock.NewResult(0, 0))
+	prep.ExpectExec().WithArgs("2", "b").WillReturnResult(sqlmock.NewResult(0, 0))
+	prep.ExpectExec().WithArgs().WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	err := CopyFrom(ctx, "book", []string{"id", "title"}, [][]any{{"1", "a"}, {"2", "b"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCopyFrom_InTransaction(t *testing.T) {
+	ctx, mock, _ := setupTest(t)
+
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare(`COPY "book" \("id"\) FROM STDIN`)
+	prep.ExpectExec().WithArgs("1").WillReturnResult(sqlmock.NewResult(0, 0))
+	prep.ExpectExec().WithArgs().WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := Atomic(ctx, func(ctx context.Context) error {
+		return CopyFrom(ctx, "book", []string{"id"}, [][]any{{"1"}})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -4,10 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"math/rand"
 	"time"
 
-	"github.com/lib/pq"
 	"github.com/sqlbunny/errors"
 )
 
@@ -23,6 +21,10 @@ type contextDBKeyType struct{}
 var ContextDBKey = contextDBKeyType{}
 
 func ContextWithDB(ctx context.Context, db DB) context.Context {
+	// The replicas set with ContextWithDBs are those of the previous database.
+	if ctx.Value(contextReplicasKey) != nil {
+		ctx = context.WithValue(ctx, contextReplicasKey, nil)
+	}
 	return context.WithValue(ctx, ContextDBKey, db)
 }
 
@@ -38,7 +40,7 @@ func Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
 	db := DBFromContext(ctx)
 	begin := time.Now()
 	res, err := db.ExecContext(ctx, query, args...)
-	err = errors.WithStack(err)
+	err = errors.WithStack(ClassifyError(err))
 	logger.LogQuery(ctx, QueryLogInfo{
 		Query:    query,
 		Duration: time.Since(begin),
@@ -48,11 +50,13 @@ func Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
 	return res, err
 }
 
+// Query runs query on the primary database, or on a replica with a
+// ReadReplica context.
 func Query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
-	db := DBFromContext(ctx)
+	db := queryDBFromContext(ctx)
 	begin := time.Now()
 	res, err := db.QueryContext(ctx, query, args...)
-	err = errors.WithStack(err)
+	err = errors.WithStack(ClassifyError(err))
 	logger.LogQuery(ctx, QueryLogInfo{
 		Query:    query,
 		Duration: time.Since(begin),
@@ -62,8 +66,10 @@ func Query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
 	return res, err
 }
 
+// QueryRow runs query on the primary database, or on a replica with a
+// ReadReplica context, like Query.
 func QueryRow(ctx context.Context, query string, args ...any) *sql.Row {
-	db := DBFromContext(ctx)
+	db := queryDBFromContext(ctx)
 	begin := time.Now()
 	res := db.QueryRowContext(ctx, query, args...)
 	logger.LogQuery(ctx, QueryLogInfo{
@@ -79,6 +85,7 @@ func QueryRow(ctx context.Context, query string, args ...any) *sql.Row {
 type AtomicOptions struct {
 	ReadOnly  bool
 	Isolation sql.IsolationLevel // 0 means use default (Serializable)
+	Retry     RetryPolicy
 }
 
 // Atomic invokes the passed function in the context of a managed SQL
@@ -94,6 +101,8 @@ func Atomic(ctx context.Context, fn func(ctx context.Context) error) error {
 // read only transaction. Any errors returned from the user-supplied function
 // are returned from this function.
 //
+// The transaction runs on a replica if the context has some, see ContextWithDBs.
+//
 // Retries are automatically performed in case of serialization failures or deadlocks.
 func AtomicReadOnly(ctx context.Context, fn func(ctx context.Context) error) error {
 	return doAtomic(ctx, fn, AtomicOptions{ReadOnly: true})
@@ -102,7 +111,8 @@ func AtomicReadOnly(ctx context.Context, fn func(ctx context.Context) error) err
 // AtomicWithOptions invokes the passed function in the context of a managed SQL
 // transaction with the given options.
 //
-// Retries are automatically performed in case of serialization failures or deadlocks.
+// Retries are automatically performed in case of serialization failures or deadlocks,
+// as configured by opts.Retry.
 func AtomicWithOptions(ctx context.Context, opts AtomicOptions, fn func(ctx context.Context) error) error {
 	return doAtomic(ctx, fn, opts)
 }
@@ -116,26 +126,43 @@ func doAtomic(ctx context.Context, fn func(ctx context.Context) error, opts Atom
 		return doTransaction(ctx, fn, opts)
 	}
 
-	var err error
-	for try := uint(0); try < 12; try++ {
-		err = doTransaction(ctx, fn, opts)
+	maxAttempts := opts.Retry.maxAttempts()
+	for attempt := 1; ; attempt++ {
+		err := doTransaction(ctx, fn, opts)
 		if err == nil {
 			return nil
 		}
 
-		if !shouldRetryTransaction(err) {
+		// The transaction is committed if its OnCommit hooks failed.
+		if _, ok := AsPostCommitError(err); ok {
+			return err
+		}
+
+		if attempt == maxAttempts || !opts.Retry.shouldRetry(err) {
 			return err
 		}
 
-		time.Sleep(time.Millisecond * time.Duration(rand.Int31n(1<<try)))
+		delay := opts.Retry.backoff(attempt)
+		if l, ok := logger.(RetryLogger); ok {
+			l.LogRetry(ctx, RetryLogInfo{
+				Attempt: attempt,
+				Delay:   delay,
+				Err:     err,
+			})
+		}
+		if opts.Retry.OnRetry != nil {
+			opts.Retry.OnRetry(ctx, attempt, err)
+		}
+
+		if err2 := sleep(ctx, delay); err2 != nil {
+			return errors.Errorf("retry canceled: %w, after: %w", err2, err)
+		}
 	}
-	return err
 }
 
 func shouldRetryTransaction(err error) bool {
-	var pqerr *pq.Error
-	if errors.As(err, &pqerr) {
-		switch pqerr.Code {
+	if pgerr, ok := currentDriver.PgError(err); ok {
+		switch pgerr.Code {
 		case "40001": // serialization_failures
 			return true
 		case "40P01": // deadlock_detected
@@ -146,11 +173,19 @@ func shouldRetryTransaction(err error) bool {
 }
 
 type txNode struct {
-	dbTx     *sql.Tx
+	dbTx *sql.Tx
+	// conn is the connection of the transaction, if its database is a
+	// *sql.DB or a *sql.Conn. It's closed with the transaction if ownsConn.
+	conn     *sql.Conn
+	ownsConn bool
 	parent   *txNode
 	child    *txNode
 	depth    int
-	onCommit []func(context.Context) error
+	readOnly bool
+
+	onCommit            []func(context.Context) error
+	onRollback          []func(context.Context)
+	onSavepointRollback []func(context.Context)
 }
 
 func (t *txNode) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
@@ -174,6 +209,7 @@ func (t *txNode) QueryRowContext(ctx context.Context, query string, args ...any)
 
 func (t *txNode) Commit() error {
 	if t.parent == nil {
+		defer t.closeConn()
 		return t.dbTx.Commit()
 	}
 	_, err := t.dbTx.Exec(fmt.Sprintf("RELEASE SAVEPOINT savepoint_%d", t.depth))
@@ -183,6 +219,7 @@ func (t *txNode) Commit() error {
 
 func (t *txNode) Rollback() error {
 	if t.parent == nil {
+		defer t.closeConn()
 		return t.dbTx.Rollback()
 	}
 	_, err := t.dbTx.Exec(fmt.Sprintf("ROLLBACK TO SAVEPOINT savepoint_%d", t.depth))
@@ -190,26 +227,47 @@ func (t *txNode) Rollback() error {
 	return err
 }
 
+func (t *txNode) closeConn() {
+	if t.ownsConn {
+		_ = t.conn.Close()
+	}
+}
+
 func (t *txNode) runOnCommit(ctx context.Context) error {
 	if t.parent != nil {
 		// If we're in a subtransaction, we don't want to execute the onCommits yet.
 		// We append them to the parent transaction, so they'll run when the topmost transaction commits.
+		// The onRollbacks too, as the changes of the subtransaction are now those of the parent.
 		t.parent.onCommit = append(t.parent.onCommit, t.onCommit...)
+		t.parent.onRollback = append(t.parent.onRollback, t.onRollback...)
 		return nil
 	}
 
 	// We are the top most transaction.
-	// Just run the onCommit hooks.
+	// Run all the onCommit hooks, since the transaction is committed anyway.
+	var errs []error
 	for _, fn := range t.onCommit {
-		err := fn(ctx)
-		if err != nil {
-			return err
+		if err := fn(ctx); err != nil {
+			errs = append(errs, err)
 		}
 	}
+	if len(errs) != 0 {
+		return errors.WithStack(&PostCommitError{Errs: errs})
+	}
 
 	return nil
 }
 
+func (t *txNode) runOnRollback(ctx context.Context) {
+	// The onCommits are dropped with the changes of the transaction.
+	for _, fn := range t.onSavepointRollback {
+		fn(ctx)
+	}
+	for _, fn := range t.onRollback {
+		fn(ctx)
+	}
+}
+
 type beginTxer interface {
 	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
 }
@@ -228,14 +286,48 @@ func doTransaction(ctx context.Context, fn func(ctx context.Context) error, opts
 		isolation = sql.LevelSerializable
 	}
 
+	db := DBFromContext(ctx)
+	if opts.ReadOnly {
+		db = readDBFromContext(ctx)
+	}
+
 	var node *txNode
-	switch db := DBFromContext(ctx).(type) {
+	switch db := db.(type) {
 	case beginTxer:
-		tx, err := db.BeginTx(ctx, &sql.TxOptions{
+		node = &txNode{
+			depth:    0,
+			readOnly: opts.ReadOnly,
+		}
+
+		// The transaction is started on a connection of its own, which
+		// drivers need for statements like COPY.
+		switch db := db.(type) {
+		case *sql.DB:
+			conn, err := db.Conn(ctx)
+			if err != nil {
+				retErr := errors.Errorf("BeginTx failed: %w", err)
+				logger.LogRollback(ctx, RollbackLogInfo{
+					Duration: time.Since(begin),
+					Err:      retErr,
+				})
+				return retErr
+			}
+			node.conn = conn
+			node.ownsConn = true
+		case *sql.Conn:
+			node.conn = db
+		}
+
+		var txer beginTxer = db
+		if node.conn != nil {
+			txer = node.conn
+		}
+		tx, err := txer.BeginTx(ctx, &sql.TxOptions{
 			Isolation: isolation,
 			ReadOnly:  opts.ReadOnly,
 		})
 		if err != nil {
+			node.closeConn()
 			retErr := errors.Errorf("BeginTx failed: %w", err)
 			logger.LogRollback(ctx, RollbackLogInfo{
 				Duration: time.Since(begin),
@@ -243,15 +335,14 @@ func doTransaction(ctx context.Context, fn func(ctx context.Context) error, opts
 			})
 			return retErr
 		}
-		node = &txNode{
-			dbTx:  tx,
-			depth: 0,
-		}
+		node.dbTx = tx
 	case *txNode:
 		node = &txNode{
-			dbTx:   db.dbTx,
-			parent: db,
-			depth:  db.depth + 1,
+			dbTx:     db.dbTx,
+			conn:     db.conn,
+			parent:   db,
+			depth:    db.depth + 1,
+			readOnly: db.readOnly,
 		}
 		_, err := db.dbTx.Exec(fmt.Sprintf("SAVEPOINT savepoint_%d", node.depth))
 		if err != nil {
@@ -278,7 +369,8 @@ func doTransaction(ctx context.Context, fn func(ctx context.Context) error, opts
 				Err:      fmt.Errorf("panic %v", p),
 			})
 			_ = node.Rollback() // just ignore errors here.
-			panic(p)            // re-throw panic.
+			node.runOnRollback(ctx)
+			panic(p) // re-throw panic.
 		} else if err != nil {
 			logger.LogRollback(ctx, RollbackLogInfo{
 				Duration: time.Since(begin),
@@ -288,6 +380,7 @@ func doTransaction(ctx context.Context, fn func(ctx context.Context) error, opts
 			if err2 != nil {
 				err = errors.Errorf("tx failed: %w, and rollback failed: %w", err, err2)
 			}
+			node.runOnRollback(ctx)
 		}
 	}()
 
@@ -305,12 +398,7 @@ func doTransaction(ctx context.Context, fn func(ctx context.Context) error, opts
 		Duration: time.Since(begin),
 	})
 
-	err = node.runOnCommit(ctx)
-	if err != nil {
-		return errors.Errorf("OnCommit returned error: %w", err)
-	}
-
-	return nil
+	return node.runOnCommit(ctx)
 }
 
 func IsAtomic(ctx context.Context) bool {
@@ -330,12 +418,65 @@ func AssertNotAtomic(ctx context.Context) {
 	}
 }
 
+// OnCommit registers fn to run after the transaction of ctx commits. In a
+// nested Atomic, fn runs after the outermost transaction commits, and is
+// dropped if the nested Atomic is rolled back.
+//
+// All the hooks run even if some fail. Atomic then returns a
+// *PostCommitError with their errors, and isn't retried: the transaction is
+// committed.
 func OnCommit(ctx context.Context, fn func(context.Context) error) {
-	db := DBFromContext(ctx)
-	tx, ok := db.(*txNode)
+	tx := txFromContext(ctx, "OnCommit")
+	tx.onCommit = append(tx.onCommit, fn)
+}
+
+// OnRollback registers fn to run after the changes made in the transaction of
+// ctx are rolled back: when the nested Atomic of ctx is rolled back, or when
+// an enclosing transaction is after it committed.
+//
+// fn runs once for every rolled back attempt of a retried transaction.
+func OnRollback(ctx context.Context, fn func(context.Context)) {
+	tx := txFromContext(ctx, "OnRollback")
+	tx.onRollback = append(tx.onRollback, fn)
+}
+
+// OnSavepointRollback registers fn to run after the nested Atomic of ctx is
+// rolled back to its savepoint, before the enclosing transaction goes on.
+// Unlike OnRollback, fn doesn't run when an enclosing transaction is rolled
+// back after the nested Atomic committed.
+func OnSavepointRollback(ctx context.Context, fn func(context.Context)) {
+	tx := txFromContext(ctx, "OnSavepointRollback")
+	if tx.parent == nil {
+		panic("OnSavepointRollback called while not in a nested atomic")
+	}
+	tx.onSavepointRollback = append(tx.onSavepointRollback, fn)
+}
+
+func txFromContext(ctx context.Context, caller string) *txNode {
+	tx, ok := DBFromContext(ctx).(*txNode)
 	if !ok {
-		panic("OnCommit called while not in atomic")
+		panic(caller + " called while not in atomic")
 	}
+	return tx
+}
 
-	tx.onCommit = append(tx.onCommit, fn)
+// TransactionInfo describes the transaction of a context.
+type TransactionInfo struct {
+	// Depth is 0 in an Atomic, 1 in an Atomic nested in it, and so on.
+	Depth int
+	// ReadOnly is whether the outermost transaction is read only. Nested
+	// Atomic calls run in the mode of the outermost one.
+	ReadOnly bool
+}
+
+// TxInfo returns the transaction of ctx, and whether ctx is in one.
+func TxInfo(ctx context.Context) (TransactionInfo, bool) {
+	tx, ok := DBFromContext(ctx).(*txNode)
+	if !ok {
+		return TransactionInfo{}, false
+	}
+	return TransactionInfo{
+		Depth:    tx.depth,
+		ReadOnly: tx.readOnly,
+	}, true
 }
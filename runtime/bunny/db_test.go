@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/lib/pq"
 	"github.com/sqlbunny/errors"
@@ -296,11 +297,13 @@ func TestAtomic_OnCommit_Error(t *testing.T) {
 		return nil
 	})
 
-	rec.record(fmt.Sprintf("err is errTest=%v", errors.Is(err, errTest)))
+	_, isPostCommit := AsPostCommitError(err)
+	rec.record(fmt.Sprintf("err is errTest=%v isPostCommit=%v", errors.Is(err, errTest), isPostCommit))
 	rec.check(t, []string{
 		"fn",
 		"oncommit1",
-		"err is errTest=true",
+		"oncommit2",
+		"err is errTest=true isPostCommit=true",
 	})
 }
 
@@ -658,3 +661,217 @@ func TestAtomic_BeginError(t *testing.T) {
 		"err contains test error=true",
 	})
 }
+
+// retryLogger records the retries logged.
+type retryLogger struct {
+	dummyLogger
+	rec *recorder
+}
+
+func (l *retryLogger) LogRetry(ctx context.Context, info RetryLogInfo) {
+	l.rec.record(fmt.Sprintf("log retry attempt=%d delay=%v", info.Attempt, info.Delay))
+}
+
+func TestAtomic_RetryPolicy(t *testing.T) {
+	ctx, mock, rec := setupTest(t)
+	SetLogger(&retryLogger{rec: rec})
+	t.Cleanup(func() { SetLogger(&dummyLogger{}) })
+
+	for i := 0; i < 3; i++ {
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+	}
+
+	attempt := 0
+	err := AtomicWithOptions(ctx, AtomicOptions{
+		Retry: RetryPolicy{
+			MaxAttempts: 3,
+			Backoff:     func(attempt int) time.Duration { return time.Duration(attempt) * time.Microsecond },
+			Retryable:   func(err error) bool { return errors.Is(err, errTest) },
+			OnRetry: func(ctx context.Context, attempt int, err error) {
+				rec.record(fmt.Sprintf("on retry attempt=%d", attempt))
+			},
+		},
+	}, func(ctx context.Context) error {
+		attempt++
+		rec.record(fmt.Sprintf("fn attempt=%d", attempt))
+		if attempt == 1 {
+			return errSerialization
+		}
+		return errTest
+	})
+
+	rec.record(fmt.Sprintf("err is errTest=%v", errors.Is(err, errTest)))
+	rec.check(t, []string{
+		"fn attempt=1",
+		"log retry attempt=1 delay=1µs",
+		"on retry attempt=1",
+		"fn attempt=2",
+		"log retry attempt=2 delay=2µs",
+		"on retry attempt=2",
+		"fn attempt=3",
+		"err is errTest=true",
+	})
+}
+
+func TestAtomic_RetryContextCanceled(t *testing.T) {
+	ctx, mock, rec := setupTest(t)
+	ctx, cancel := context.WithCancel(ctx)
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	err := AtomicWithOptions(ctx, AtomicOptions{
+		Retry: RetryPolicy{
+			Backoff: func(attempt int) time.Duration { return time.Hour },
+			OnRetry: func(ctx context.Context, attempt int, err error) { cancel() },
+		},
+	}, func(ctx context.Context) error {
+		rec.record("fn")
+		return errDeadlock
+	})
+
+	var pqErr *pq.Error
+	rec.record(fmt.Sprintf("canceled=%v isPQ=%v", errors.Is(err, context.Canceled), errors.As(err, &pqErr)))
+	rec.check(t, []string{
+		"fn",
+		"canceled=true isPQ=true",
+	})
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+	for attempt, max := range []time.Duration{10, 20, 40, 50, 50} {
+		if d := backoff(attempt + 1); d < 0 || d >= max*time.Millisecond {
+			t.Errorf("attempt %d: bad delay %v", attempt+1, d)
+		}
+	}
+}
+
+func TestAtomic_OnCommit_ErrorNotRetried(t *testing.T) {
+	ctx, mock, rec := setupTest(t)
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	err := Atomic(ctx, func(ctx context.Context) error {
+		rec.record("fn")
+		OnCommit(ctx, func(ctx context.Context) error {
+			rec.record("oncommit")
+			return errSerialization
+		})
+		return nil
+	})
+
+	_, isPostCommit := AsPostCommitError(err)
+	rec.record(fmt.Sprintf("isPostCommit=%v", isPostCommit))
+	rec.check(t, []string{
+		"fn",
+		"oncommit",
+		"isPostCommit=true",
+	})
+}
+
+func TestAtomic_OnRollback(t *testing.T) {
+	ctx, mock, rec := setupTest(t)
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	err := Atomic(ctx, func(ctx context.Context) error {
+		rec.record("fn")
+		OnRollback(ctx, func(ctx context.Context) {
+			rec.record(fmt.Sprintf("onrollback atomic=%v", IsAtomic(ctx)))
+		})
+		OnCommit(ctx, func(ctx context.Context) error {
+			rec.record("oncommit")
+			return nil
+		})
+		return errTest
+	})
+
+	rec.record(fmt.Sprintf("err is errTest=%v", errors.Is(err, errTest)))
+	rec.check(t, []string{
+		"fn",
+		"onrollback atomic=false",
+		"err is errTest=true",
+	})
+}
+
+func TestAtomic_Nested_OnRollback(t *testing.T) {
+	ctx, mock, rec := setupTest(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT savepoint_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT savepoint_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SAVEPOINT savepoint_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("RELEASE SAVEPOINT savepoint_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := Atomic(ctx, func(ctx context.Context) error {
+		OnRollback(ctx, func(ctx context.Context) {
+			rec.record("outer onrollback")
+		})
+		_ = Atomic(ctx, func(ctx context.Context) error {
+			OnRollback(ctx, func(ctx context.Context) {
+				rec.record(fmt.Sprintf("inner1 onrollback atomic=%v", IsAtomic(ctx)))
+			})
+			OnSavepointRollback(ctx, func(ctx context.Context) {
+				rec.record("inner1 onsavepointrollback")
+			})
+			return errTest
+		})
+		rec.record("after inner1")
+		_ = Atomic(ctx, func(ctx context.Context) error {
+			OnRollback(ctx, func(ctx context.Context) {
+				rec.record("inner2 onrollback")
+			})
+			OnSavepointRollback(ctx, func(ctx context.Context) {
+				rec.record("inner2 onsavepointrollback")
+			})
+			return nil
+		})
+		rec.record("after inner2")
+		return errTest
+	})
+
+	rec.record(fmt.Sprintf("err is errTest=%v", errors.Is(err, errTest)))
+	rec.check(t, []string{
+		"inner1 onsavepointrollback",
+		"inner1 onrollback atomic=true",
+		"after inner1",
+		"after inner2",
+		"outer onrollback",
+		"inner2 onrollback",
+		"err is errTest=true",
+	})
+}
+
+func TestTxInfo(t *testing.T) {
+	ctx, mock, rec := setupTest(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT savepoint_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("RELEASE SAVEPOINT savepoint_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, ok := TxInfo(ctx)
+	rec.record(fmt.Sprintf("ok=%v", ok))
+	err := AtomicReadOnly(ctx, func(ctx context.Context) error {
+		info, ok := TxInfo(ctx)
+		rec.record(fmt.Sprintf("ok=%v depth=%d readOnly=%v", ok, info.Depth, info.ReadOnly))
+		return Atomic(ctx, func(ctx context.Context) error {
+			info, ok := TxInfo(ctx)
+			rec.record(fmt.Sprintf("ok=%v depth=%d readOnly=%v", ok, info.Depth, info.ReadOnly))
+			return nil
+		})
+	})
+
+	rec.record(fmt.Sprintf("err=%v", err))
+	rec.check(t, []string{
+		"ok=false",
+		"ok=true depth=0 readOnly=true",
+		"ok=true depth=1 readOnly=true",
+		"err=<nil>",
+	})
+}
@@ -0,0 +1,101 @@
+package bunny
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/lib/pq"
+	"github.com/sqlbunny/errors"
+)
+
+// Driver adapts bunny to the Postgres driver behind database/sql, for what
+// database/sql doesn't abstract: the errors of the database, the encoding of
+// arrays and the COPY protocol.
+//
+// The default driver is lib/pq. Use SetDriver to run on another one, like
+// pgx with the pgxdriver package.
+type Driver interface {
+	// PgError returns the fields of the Postgres error in the chain of err, if any.
+	PgError(err error) (*PgError, bool)
+
+	// Array returns a query argument for a slice like []int64 or []string,
+	// which can also be passed to Scan if a is a pointer to a slice.
+	Array(a any) ArrayValue
+
+	// CopyFrom inserts rows into the columns of table using the COPY protocol,
+	// in the transaction tx started on conn. conn is nil if the database of the
+	// transaction is not a *sql.DB or a *sql.Conn.
+	CopyFrom(ctx context.Context, conn *sql.Conn, tx *sql.Tx, table string, columns []string, rows [][]any) error
+}
+
+// ArrayValue is a Postgres array query argument and Scan destination.
+type ArrayValue interface {
+	driver.Valuer
+	sql.Scanner
+}
+
+// PgError holds the fields of a Postgres error used by bunny.
+type PgError struct {
+	// Code is the SQLSTATE code of the error, like "23505".
+	Code       string
+	Table      string
+	Column     string
+	Constraint string
+	Detail     string
+}
+
+var currentDriver Driver = PQDriver{}
+
+// SetDriver sets the driver used by bunny. It must be called before running
+// any query.
+func SetDriver(d Driver) {
+	currentDriver = d
+}
+
+// Array returns a query argument for the slice a, or a Scan destination for
+// the pointer to a slice a, using the current driver.
+func Array(a any) ArrayValue {
+	return currentDriver.Array(a)
+}
+
+// PQDriver is the Driver for lib/pq.
+type PQDriver struct{}
+
+func (PQDriver) PgError(err error) (*PgError, bool) {
+	var pqerr *pq.Error
+	if !errors.As(err, &pqerr) {
+		return nil, false
+	}
+	return &PgError{
+		Code:       string(pqerr.Code),
+		Table:      pqerr.Table,
+		Column:     pqerr.Column,
+		Constraint: pqerr.Constraint,
+		Detail:     pqerr.Detail,
+	}, true
+}
+
+func (PQDriver) Array(a any) ArrayValue {
+	return pq.Array(a)
+}
+
+func (PQDriver) CopyFrom(ctx context.Context, conn *sql.Conn, tx *sql.Tx, table string, columns []string, rows [][]any) error {
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(table, columns...))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	// Executing without arguments flushes the rows.
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package bunny
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/sqlbunny/errors"
 )
@@ -27,3 +28,246 @@ type InvalidEnumError struct {
 func (e *InvalidEnumError) Error() string {
 	return fmt.Sprintf("Invalid %s '%s'", e.Type, e.Value)
 }
+
+// UniqueViolationError is returned when a statement violates a unique
+// constraint or the primary key of a model.
+type UniqueViolationError struct {
+	// Model is the name of the model of the constraint.
+	Model string
+	// Fields are the paths of the fields of the constraint, like "price.currency".
+	Fields []string
+	// PrimaryKey is set if the constraint is the primary key.
+	PrimaryKey bool
+	// Constraint is the name of the constraint in the database.
+	Constraint string
+	// Err is the error of the database driver.
+	Err error
+}
+
+func (e *UniqueViolationError) Error() string {
+	return fmt.Sprintf("sqlbunny: unique violation on %s%s", e.Model, fieldList(e.Fields))
+}
+
+func (e *UniqueViolationError) Unwrap() error {
+	return e.Err
+}
+
+// ForeignKeyViolationError is returned when a statement violates a foreign
+// key, either by referencing a missing row or by deleting a referenced one.
+type ForeignKeyViolationError struct {
+	// Model is the name of the model with the foreign key.
+	Model string
+	// Fields are the paths of the local fields of the foreign key.
+	Fields []string
+	// Constraint is the name of the constraint in the database.
+	Constraint string
+	// Err is the error of the database driver.
+	Err error
+}
+
+func (e *ForeignKeyViolationError) Error() string {
+	return fmt.Sprintf("sqlbunny: foreign key violation on %s%s", e.Model, fieldList(e.Fields))
+}
+
+func (e *ForeignKeyViolationError) Unwrap() error {
+	return e.Err
+}
+
+// NotNullViolationError is returned when a statement sets a non-nullable
+// field to null.
+type NotNullViolationError struct {
+	// Model is the name of the model of the field.
+	Model string
+	// Field is the path of the field.
+	Field string
+	// Err is the error of the database driver.
+	Err error
+}
+
+func (e *NotNullViolationError) Error() string {
+	return fmt.Sprintf("sqlbunny: not null violation on %s (%s)", e.Model, e.Field)
+}
+
+func (e *NotNullViolationError) Unwrap() error {
+	return e.Err
+}
+
+// CheckViolationError is returned when a statement violates a check
+// constraint of a model.
+type CheckViolationError struct {
+	// Model is the name of the model of the check.
+	Model string
+	// Name is the name of the check, as given in the model definition.
+	Name string
+	// Constraint is the name of the constraint in the database.
+	Constraint string
+	// Err is the error of the database driver.
+	Err error
+}
+
+func (e *CheckViolationError) Error() string {
+	return fmt.Sprintf("sqlbunny: check violation on %s (%s)", e.Model, e.Name)
+}
+
+func (e *CheckViolationError) Unwrap() error {
+	return e.Err
+}
+
+// PostCommitError is returned by Atomic when OnCommit hooks failed after the
+// transaction committed.
+type PostCommitError struct {
+	// Errs are the errors of the failed hooks, in the order they ran.
+	Errs []error
+}
+
+func (e *PostCommitError) Error() string {
+	msgs := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		msgs[i] = err.Error()
+	}
+	return "sqlbunny: OnCommit hooks failed: " + strings.Join(msgs, "; ")
+}
+
+func (e *PostCommitError) Unwrap() []error {
+	return e.Errs
+}
+
+// AsUniqueViolationError returns the UniqueViolationError in the chain of err, if any.
+func AsUniqueViolationError(err error) (*UniqueViolationError, bool) {
+	var e *UniqueViolationError
+	return e, errors.As(err, &e)
+}
+
+// AsForeignKeyViolationError returns the ForeignKeyViolationError in the chain of err, if any.
+func AsForeignKeyViolationError(err error) (*ForeignKeyViolationError, bool) {
+	var e *ForeignKeyViolationError
+	return e, errors.As(err, &e)
+}
+
+// AsNotNullViolationError returns the NotNullViolationError in the chain of err, if any.
+func AsNotNullViolationError(err error) (*NotNullViolationError, bool) {
+	var e *NotNullViolationError
+	return e, errors.As(err, &e)
+}
+
+// AsCheckViolationError returns the CheckViolationError in the chain of err, if any.
+func AsCheckViolationError(err error) (*CheckViolationError, bool) {
+	var e *CheckViolationError
+	return e, errors.As(err, &e)
+}
+
+// AsPostCommitError returns the PostCommitError in the chain of err, if any.
+func AsPostCommitError(err error) (*PostCommitError, bool) {
+	var e *PostCommitError
+	return e, errors.As(err, &e)
+}
+
+// ClassifyError returns err as one of the constraint violation errors if it
+// is a constraint violation reported by the database, or else err as is.
+// Exec and Query classify their errors, and so do the generated models.
+// An error that is already classified is returned as is.
+func ClassifyError(err error) error {
+	if isClassified(err) {
+		return err
+	}
+
+	pgerr, ok := currentDriver.PgError(err)
+	if !ok {
+		return err
+	}
+
+	switch pgerr.Code {
+	case "23505": // unique_violation
+		model, fields, _ := parseConstraintName(pgerr.Constraint, "key")
+		e := &UniqueViolationError{Model: model, Fields: fields, Constraint: pgerr.Constraint, Err: err}
+		if model == "" {
+			e.Model = pgerr.Table
+			e.PrimaryKey = pgerr.Constraint == pgerr.Table+"_pkey"
+			e.Fields = parseDetailFields(pgerr.Detail)
+		}
+		return e
+	case "23503": // foreign_key_violation
+		model, fields, _ := parseConstraintName(pgerr.Constraint, "fkey")
+		e := &ForeignKeyViolationError{Model: model, Fields: fields, Constraint: pgerr.Constraint, Err: err}
+		if model == "" {
+			e.Model = pgerr.Table
+			e.Fields = parseDetailFields(pgerr.Detail)
+		}
+		return e
+	case "23502": // not_null_violation
+		return &NotNullViolationError{Model: pgerr.Table, Field: fieldPath(pgerr.Column), Err: err}
+	case "23514": // check_violation
+		model, names, _ := parseConstraintName(pgerr.Constraint, "check")
+		e := &CheckViolationError{Model: model, Constraint: pgerr.Constraint, Err: err}
+		if model == "" || len(names) != 1 {
+			e.Model = pgerr.Table
+			e.Name = pgerr.Constraint
+		} else {
+			e.Name = names[0]
+		}
+		return e
+	}
+	return err
+}
+
+// isClassified reports whether err already has a constraint violation error
+// in its chain.
+func isClassified(err error) bool {
+	if _, ok := AsUniqueViolationError(err); ok {
+		return true
+	}
+	if _, ok := AsForeignKeyViolationError(err); ok {
+		return true
+	}
+	if _, ok := AsNotNullViolationError(err); ok {
+		return true
+	}
+	_, ok := AsCheckViolationError(err)
+	return ok
+}
+
+// parseConstraintName parses the model and the field paths of a constraint
+// name made by sqlbunny, like "model___field1___field2___suffix". Columns
+// of struct fields, like "price__currency", are returned as "price.currency".
+func parseConstraintName(name string, suffix string) (string, []string, bool) {
+	parts := strings.Split(name, "___")
+	if len(parts) < 3 || parts[len(parts)-1] != suffix {
+		return "", nil, false
+	}
+
+	fields := make([]string, len(parts)-2)
+	for i, c := range parts[1 : len(parts)-1] {
+		fields[i] = fieldPath(c)
+	}
+	return parts[0], fields, true
+}
+
+// parseDetailFields parses the field paths of an error detail like
+// "Key (author_id, title)=(a, b) already exists.".
+func parseDetailFields(detail string) []string {
+	rest, ok := strings.CutPrefix(detail, "Key (")
+	if !ok {
+		return nil
+	}
+	cols, _, ok := strings.Cut(rest, ")=")
+	if !ok {
+		return nil
+	}
+
+	var fields []string
+	for _, c := range strings.Split(cols, ", ") {
+		fields = append(fields, fieldPath(strings.Trim(c, `"`)))
+	}
+	return fields
+}
+
+func fieldPath(column string) string {
+	return strings.ReplaceAll(column, "__", ".")
+}
+
+func fieldList(fields []string) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(fields, ", ") + ")"
+}
@@ -0,0 +1,110 @@
+package bunny
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/sqlbunny/errors"
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+func TestClassifyError(t *testing.T) {
+	t.Parallel()
+
+	unique := &pq.Error{Code: "23505", Table: "book", Constraint: "book___price__currency___title___key"}
+	e, ok := AsUniqueViolationError(ClassifyError(errors.WithStack(unique)))
+	if !ok {
+		t.Fatal("expected a UniqueViolationError")
+	}
+	if e.Model != "book" || !reflect.DeepEqual(e.Fields, []string{"price.currency", "title"}) || e.PrimaryKey {
+		t.Errorf("bad error: %#v", e)
+	}
+	if e.Error() != "sqlbunny: unique violation on book (price.currency, title)" {
+		t.Errorf("bad message: %s", e.Error())
+	}
+	var pqerr *pq.Error
+	if !errors.As(e, &pqerr) || pqerr != unique {
+		t.Error("expected the driver error to be unwrapped")
+	}
+
+	pkey := &pq.Error{Code: "23505", Table: "book", Constraint: "book_pkey", Detail: "Key (id)=(1) already exists."}
+	e, ok = AsUniqueViolationError(ClassifyError(pkey))
+	if !ok || e.Model != "book" || !e.PrimaryKey || !reflect.DeepEqual(e.Fields, []string{"id"}) {
+		t.Errorf("bad error: %#v", e)
+	}
+
+	fkey := &pq.Error{Code: "23503", Table: "book", Constraint: "book___author_id___fkey"}
+	fe, ok := AsForeignKeyViolationError(ClassifyError(fkey))
+	if !ok || fe.Model != "book" || !reflect.DeepEqual(fe.Fields, []string{"author_id"}) {
+		t.Errorf("bad error: %#v", fe)
+	}
+
+	notNull := &pq.Error{Code: "23502", Table: "book", Column: "price__amount"}
+	ne, ok := AsNotNullViolationError(ClassifyError(notNull))
+	if !ok || ne.Model != "book" || ne.Field != "price.amount" {
+		t.Errorf("bad error: %#v", ne)
+	}
+
+	check := &pq.Error{Code: "23514", Table: "book", Constraint: "book___positive___check"}
+	ce, ok := AsCheckViolationError(ClassifyError(check))
+	if !ok || ce.Model != "book" || ce.Name != "positive" {
+		t.Errorf("bad error: %#v", ce)
+	}
+
+	other := &pq.Error{Code: "23514", Table: "book", Constraint: "legacy_check"}
+	ce, ok = AsCheckViolationError(ClassifyError(other))
+	if !ok || ce.Model != "book" || ce.Name != "legacy_check" {
+		t.Errorf("bad error: %#v", ce)
+	}
+
+	if err := ClassifyError(errTest); err != errTest {
+		t.Errorf("expected the error as is, got %v", err)
+	}
+	if err := ClassifyError(nil); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+}
+
+func TestClassifyErrorIdempotent(t *testing.T) {
+	t.Parallel()
+
+	unique := &pq.Error{Code: "23505", Table: "book", Constraint: "book___title___key"}
+	classified := ClassifyError(unique)
+	if err := ClassifyError(classified); err != classified {
+		t.Errorf("expected the classified error as is, got %#v", err)
+	}
+
+	wrapped := errors.WithStack(classified)
+	if err := ClassifyError(wrapped); err != wrapped {
+		t.Errorf("expected the wrapped error as is, got %#v", err)
+	}
+
+	for _, err := range []error{
+		ClassifyError(&pq.Error{Code: "23503", Table: "book", Constraint: "book___author_id___fkey"}),
+		ClassifyError(&pq.Error{Code: "23502", Table: "book", Column: "title"}),
+		ClassifyError(&pq.Error{Code: "23514", Table: "book", Constraint: "book___positive___check"}),
+	} {
+		if got := ClassifyError(err); got != err {
+			t.Errorf("expected %T as is, got %T", err, got)
+		}
+	}
+}
+
+func TestExecClassifiesError(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := ContextWithDB(context.Background(), db)
+
+	mock.ExpectExec("INSERT").WillReturnError(&pq.Error{Code: "23505", Constraint: "author___name___key"})
+	_, err = Exec(ctx, "INSERT INTO author (name) VALUES ($1)", "a")
+	if e, ok := AsUniqueViolationError(err); !ok || e.Model != "author" {
+		t.Errorf("expected a UniqueViolationError, got %v", err)
+	}
+}
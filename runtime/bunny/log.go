@@ -25,6 +25,14 @@ type RollbackLogInfo struct {
 	Err      error
 }
 
+type RetryLogInfo struct {
+	// Attempt is the number of the failed run of the transaction, starting at 1.
+	Attempt int
+	// Delay is how long is waited before running the transaction again.
+	Delay time.Duration
+	Err   error
+}
+
 type Logger interface {
 	LogQuery(ctx context.Context, info QueryLogInfo)
 	LogBegin(ctx context.Context, info BeginLogInfo) context.Context
@@ -32,6 +40,12 @@ type Logger interface {
 	LogRollback(ctx context.Context, info RollbackLogInfo)
 }
 
+// RetryLogger is implemented by the Loggers also logging the transactions
+// run again after a serialization failure or a deadlock.
+type RetryLogger interface {
+	LogRetry(ctx context.Context, info RetryLogInfo)
+}
+
 var logger Logger = &dummyLogger{}
 
 func SetLogger(l Logger) {
@@ -0,0 +1,102 @@
+// Package pgxdriver runs bunny on pgx, through its database/sql driver:
+//
+//	bunny.SetDriver(pgxdriver.Driver{})
+//	db, err := sql.Open("pgx", dsn)
+//
+// A native pgxpool.Pool is used through database/sql with OpenDB.
+package pgxdriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/sqlbunny/errors"
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+)
+
+// Driver is the bunny.Driver for pgx.
+type Driver struct{}
+
+var _ bunny.Driver = Driver{}
+
+// OpenDB returns a *sql.DB running its queries on pool, to put in the
+// context with bunny.ContextWithDB.
+func OpenDB(pool *pgxpool.Pool) *sql.DB {
+	return stdlib.OpenDBFromPool(pool)
+}
+
+func (Driver) PgError(err error) (*bunny.PgError, bool) {
+	var pgerr *pgconn.PgError
+	if !errors.As(err, &pgerr) {
+		return nil, false
+	}
+	return &bunny.PgError{
+		Code:       pgerr.Code,
+		Table:      pgerr.TableName,
+		Column:     pgerr.ColumnName,
+		Constraint: pgerr.ConstraintName,
+		Detail:     pgerr.Detail,
+	}, true
+}
+
+func (Driver) Array(a any) bunny.ArrayValue {
+	return &array{a: a}
+}
+
+func (Driver) CopyFrom(ctx context.Context, conn *sql.Conn, tx *sql.Tx, table string, columns []string, rows [][]any) error {
+	if conn == nil {
+		return errors.New("pgxdriver: CopyFrom needs a transaction on a *sql.DB or a *sql.Conn")
+	}
+
+	// The raw connection runs the COPY in the transaction started on it.
+	return conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errors.Errorf("pgxdriver: CopyFrom needs a pgx connection, not %T", driverConn)
+		}
+		_, err := c.Conn().CopyFrom(ctx, pgx.Identifier{table}, columns, pgx.CopyFromRows(rows))
+		return errors.WithStack(err)
+	})
+}
+
+// typeMap encodes and decodes the arrays. It memoizes its plans without
+// locking, so it is used with typeMapMu held.
+var (
+	typeMap   = pgtype.NewMap()
+	typeMapMu sync.Mutex
+)
+
+// array encodes and decodes a slice in the Postgres text format, which
+// any driver accepts.
+type array struct {
+	a any
+}
+
+func (a *array) Value() (driver.Value, error) {
+	typeMapMu.Lock()
+	defer typeMapMu.Unlock()
+
+	t, ok := typeMap.TypeForValue(a.a)
+	if !ok {
+		return nil, errors.Errorf("pgxdriver: unsupported array type %T", a.a)
+	}
+	buf, err := typeMap.Encode(t.OID, pgtype.TextFormatCode, a.a, nil)
+	if err != nil || buf == nil {
+		return nil, err
+	}
+	return string(buf), nil
+}
+
+func (a *array) Scan(src any) error {
+	typeMapMu.Lock()
+	defer typeMapMu.Unlock()
+
+	return typeMap.SQLScanner(a.a).Scan(src)
+}
@@ -0,0 +1,54 @@
+package pgxdriver
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sqlbunny/errors"
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+)
+
+func TestClassifyError(t *testing.T) {
+	bunny.SetDriver(Driver{})
+	defer bunny.SetDriver(bunny.PQDriver{})
+
+	unique := &pgconn.PgError{Code: "23505", TableName: "book", ConstraintName: "book___author_id___title___key"}
+	e, ok := bunny.AsUniqueViolationError(bunny.ClassifyError(errors.WithStack(unique)))
+	if !ok {
+		t.Fatal("expected a UniqueViolationError")
+	}
+	if e.Model != "book" || !reflect.DeepEqual(e.Fields, []string{"author_id", "title"}) {
+		t.Errorf("bad error: %#v", e)
+	}
+
+	notNull := &pgconn.PgError{Code: "23502", TableName: "book", ColumnName: "title"}
+	ne, ok := bunny.AsNotNullViolationError(bunny.ClassifyError(notNull))
+	if !ok || ne.Model != "book" || ne.Field != "title" {
+		t.Errorf("bad error: %#v", ne)
+	}
+}
+
+func TestArray(t *testing.T) {
+	v, err := Driver{}.Array([]int64{1, 2}).Value()
+	if err != nil || v != "{1,2}" {
+		t.Errorf("bad value: %v, %v", v, err)
+	}
+	v, err = Driver{}.Array([]string{" a", "c"}).Value()
+	if err != nil || v != `{" a",c}` {
+		t.Errorf("bad value: %v, %v", v, err)
+	}
+	v, err = Driver{}.Array([]int64(nil)).Value()
+	if err != nil || v != nil {
+		t.Errorf("bad value: %v, %v", v, err)
+	}
+
+	var a []int64
+	if err := (Driver{}).Array(&a).Scan([]byte("{3,4}")); err != nil || !reflect.DeepEqual(a, []int64{3, 4}) {
+		t.Errorf("bad scan: %v, %v", a, err)
+	}
+	var s []string
+	if err := (Driver{}).Array(&s).Scan(`{"a b",c}`); err != nil || !reflect.DeepEqual(s, []string{"a b", "c"}) {
+		t.Errorf("bad scan: %v, %v", s, err)
+	}
+}
@@ -0,0 +1,135 @@
+package bunny
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+type contextReplicasKeyType struct{}
+
+var contextReplicasKey = contextReplicasKeyType{}
+
+type contextForcePrimaryKeyType struct{}
+
+var contextForcePrimaryKey = contextForcePrimaryKeyType{}
+
+type contextReadReplicaKeyType struct{}
+
+var contextReadReplicaKey = contextReadReplicaKeyType{}
+
+// replicaSet is the value of contextReplicasKey.
+type replicaSet struct {
+	replicas []DB
+}
+
+// ContextWithDBs returns a context running queries on primary, except for the
+// queries run with a ReadReplica context outside a transaction and the
+// AtomicReadOnly transactions, which run on one of replicas picked by the
+// ReplicaSelector. The generated One, First, All, Count, Exists and Each
+// finishers read on a replica.
+//
+// Plain Query and QueryRow stay on primary without ReadReplica, because they
+// also run writes reading back rows, like the INSERT ... RETURNING of the
+// generated Insert and Upsert, which a replica would reject. Mark reads with
+// ReadReplica to run them on a replica.
+//
+// DBFromContext returns primary.
+func ContextWithDBs(ctx context.Context, primary DB, replicas ...DB) context.Context {
+	ctx = context.WithValue(ctx, ContextDBKey, primary)
+	return context.WithValue(ctx, contextReplicasKey, &replicaSet{replicas: replicas})
+}
+
+// ForcePrimary returns a context running reads on the primary database, to
+// read rows written outside a transaction before the replicas have them.
+// It takes precedence over ReadReplica.
+func ForcePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextForcePrimaryKey, true)
+}
+
+// ReadReplica returns a context running Query and QueryRow on a replica, if
+// the context has some and isn't in a transaction. The queries must not write
+// rows.
+func ReadReplica(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextReadReplicaKey, true)
+}
+
+// queryDBFromContext returns the database running Query and QueryRow.
+func queryDBFromContext(ctx context.Context) DB {
+	if replica, _ := ctx.Value(contextReadReplicaKey).(bool); replica {
+		return readDBFromContext(ctx)
+	}
+	return DBFromContext(ctx)
+}
+
+// readDBFromContext returns the database running the reads of ctx: a replica
+// if the context has some and isn't forced to the primary or in a transaction.
+func readDBFromContext(ctx context.Context) DB {
+	db := DBFromContext(ctx)
+	if _, ok := db.(*txNode); ok {
+		return db
+	}
+	if force, _ := ctx.Value(contextForcePrimaryKey).(bool); force {
+		return db
+	}
+	set, _ := ctx.Value(contextReplicasKey).(*replicaSet)
+	if set == nil || len(set.replicas) == 0 {
+		return db
+	}
+	if replica := replicaSelector.SelectReplica(ctx, set.replicas); replica != nil {
+		return replica
+	}
+	return db
+}
+
+// ReplicaSelector picks the replica running a read.
+type ReplicaSelector interface {
+	// SelectReplica returns one of replicas, or nil to read on the primary.
+	SelectReplica(ctx context.Context, replicas []DB) DB
+}
+
+// ReplicaSelectorFunc is a function implementing ReplicaSelector.
+type ReplicaSelectorFunc func(ctx context.Context, replicas []DB) DB
+
+func (f ReplicaSelectorFunc) SelectReplica(ctx context.Context, replicas []DB) DB {
+	return f(ctx, replicas)
+}
+
+var replicaSelector ReplicaSelector = RoundRobin()
+
+// SetReplicaSelector sets how the replicas are picked. The default is RoundRobin.
+func SetReplicaSelector(s ReplicaSelector) {
+	replicaSelector = s
+}
+
+// RoundRobin returns a ReplicaSelector picking the replicas in turn.
+func RoundRobin() ReplicaSelector {
+	var next atomic.Uint64
+	return ReplicaSelectorFunc(func(ctx context.Context, replicas []DB) DB {
+		return replicas[(next.Add(1)-1)%uint64(len(replicas))]
+	})
+}
+
+// LeastLag returns a ReplicaSelector picking the replica with the least lag,
+// as returned by lag. Replicas lagging more than maxLag are skipped, and the
+// primary is used if they all are. A maxLag of 0 means no limit.
+//
+// lag is called for every replica on every read, so it should return a value
+// measured in the background rather than query the replica.
+func LeastLag(maxLag time.Duration, lag func(ctx context.Context, replica DB) time.Duration) ReplicaSelector {
+	return ReplicaSelectorFunc(func(ctx context.Context, replicas []DB) DB {
+		var best DB
+		var bestLag time.Duration
+		for _, replica := range replicas {
+			l := lag(ctx, replica)
+			if maxLag != 0 && l > maxLag {
+				continue
+			}
+			if best == nil || l < bestLag {
+				best = replica
+				bestLag = l
+			}
+		}
+		return best
+	})
+}
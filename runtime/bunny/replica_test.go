@@ -0,0 +1,131 @@
+package bunny
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+func newMock(t *testing.T) (DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, mock
+}
+
+func TestReplicas_Routing(t *testing.T) {
+	primary, primaryMock := newMock(t)
+	replica, replicaMock := newMock(t)
+	ctx := ContextWithDBs(context.Background(), primary, replica)
+
+	if DBFromContext(ctx) != primary {
+		t.Error("expected DBFromContext to return the primary")
+	}
+
+	primaryMock.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"a"}).AddRow(1))
+	var a int
+	if err := QueryRow(ctx, "SELECT 1").Scan(&a); err != nil {
+		t.Fatal(err)
+	}
+
+	replicaMock.ExpectQuery("SELECT 2").WillReturnRows(sqlmock.NewRows([]string{"a"}).AddRow(2))
+	if err := QueryRow(ReadReplica(ctx), "SELECT 2").Scan(&a); err != nil {
+		t.Fatal(err)
+	}
+
+	replicaMock.ExpectQuery("SELECT 5").WillReturnRows(sqlmock.NewRows([]string{"a"}).AddRow(5))
+	rows, err := Query(ReadReplica(ctx), "SELECT 5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+
+	primaryMock.ExpectQuery("SELECT 6").WillReturnRows(sqlmock.NewRows([]string{"a"}).AddRow(6))
+	if err := QueryRow(ForcePrimary(ReadReplica(ctx)), "SELECT 6").Scan(&a); err != nil {
+		t.Fatal(err)
+	}
+
+	primaryMock.ExpectExec("DELETE").WillReturnResult(sqlmock.NewResult(0, 1))
+	if _, err := Exec(ctx, "DELETE FROM t"); err != nil {
+		t.Fatal(err)
+	}
+
+	replicaMock.ExpectBegin()
+	replicaMock.ExpectQuery("SELECT 3").WillReturnRows(sqlmock.NewRows([]string{"a"}).AddRow(3))
+	replicaMock.ExpectCommit()
+	err = AtomicReadOnly(ctx, func(ctx context.Context) error {
+		return QueryRow(ctx, "SELECT 3").Scan(&a)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	primaryMock.ExpectBegin()
+	primaryMock.ExpectExec("SAVEPOINT savepoint_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	primaryMock.ExpectQuery("SELECT 4").WillReturnRows(sqlmock.NewRows([]string{"a"}).AddRow(4))
+	primaryMock.ExpectExec("RELEASE SAVEPOINT savepoint_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	primaryMock.ExpectCommit()
+	err = Atomic(ctx, func(ctx context.Context) error {
+		return AtomicReadOnly(ctx, func(ctx context.Context) error {
+			return QueryRow(ctx, "SELECT 4").Scan(&a)
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := primaryMock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	if err := replicaMock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReplicas_ContextWithDB(t *testing.T) {
+	primary, _ := newMock(t)
+	replica, _ := newMock(t)
+	other, _ := newMock(t)
+	ctx := ContextWithDBs(context.Background(), primary, replica)
+
+	if readDBFromContext(ctx) != replica {
+		t.Error("expected the replica")
+	}
+	if readDBFromContext(ContextWithDB(ctx, other)) != other {
+		t.Error("expected the replicas to be dropped with the primary")
+	}
+}
+
+func TestRoundRobin(t *testing.T) {
+	a, _ := newMock(t)
+	b, _ := newMock(t)
+	s := RoundRobin()
+	replicas := []DB{a, b}
+	got := []DB{
+		s.SelectReplica(context.Background(), replicas),
+		s.SelectReplica(context.Background(), replicas),
+		s.SelectReplica(context.Background(), replicas),
+	}
+	if got[0] != a || got[1] != b || got[2] != a {
+		t.Errorf("bad selection: %v", got)
+	}
+}
+
+func TestLeastLag(t *testing.T) {
+	a, _ := newMock(t)
+	b, _ := newMock(t)
+	lags := map[DB]time.Duration{a: 3 * time.Second, b: time.Second}
+	lag := func(ctx context.Context, replica DB) time.Duration { return lags[replica] }
+
+	if r := LeastLag(0, lag).SelectReplica(context.Background(), []DB{a, b}); r != b {
+		t.Error("expected the replica with the least lag")
+	}
+	if r := LeastLag(time.Millisecond, lag).SelectReplica(context.Background(), []DB{a, b}); r != nil {
+		t.Error("expected the primary when all replicas lag")
+	}
+}
@@ -0,0 +1,82 @@
+package bunny
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy configures how a transaction is retried after a serialization
+// failure or a deadlock. The zero value retries up to 12 times, with random
+// delays doubling on every attempt, starting at 1ms.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times the transaction runs at most.
+	// 0 means 12, 1 disables the retries.
+	MaxAttempts int
+
+	// Backoff returns how long to wait before running the transaction again
+	// after its attempt-th run failed, starting at 1. nil means the default
+	// backoff.
+	Backoff func(attempt int) time.Duration
+
+	// Retryable reports whether the transaction is retried after failing with
+	// err, in addition to the serialization failures and deadlocks.
+	Retryable func(err error) bool
+
+	// OnRetry is called before waiting to run the transaction again after
+	// its attempt-th run failed with err.
+	OnRetry func(ctx context.Context, attempt int, err error)
+}
+
+func (p RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts <= 0 {
+		return 12
+	}
+	return p.MaxAttempts
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if p.Backoff == nil {
+		return defaultBackoff(attempt)
+	}
+	return p.Backoff(attempt)
+}
+
+func (p RetryPolicy) shouldRetry(err error) bool {
+	return shouldRetryTransaction(err) || p.Retryable != nil && p.Retryable(err)
+}
+
+func defaultBackoff(attempt int) time.Duration {
+	return time.Millisecond * time.Duration(rand.Int63n(1<<min(attempt-1, 62)))
+}
+
+// ExponentialBackoff returns a RetryPolicy.Backoff waiting a random duration
+// up to base, doubling on every attempt up to max.
+func ExponentialBackoff(base, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		d = min(d, max)
+		if d <= 0 {
+			return 0
+		}
+		return time.Duration(rand.Int63n(int64(d)))
+	}
+}
+
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/sqlbunny/sqlbunny/runtime/bunny"
 	"github.com/sqlbunny/sqlbunny/sqlschema/operations"
+	"github.com/sqlbunny/sqlbunny/sqlschema/schema"
 )
 
 type Migration struct {
@@ -15,7 +16,21 @@ type Migration struct {
 }
 
 func (m Migration) Run(ctx context.Context) error {
+	return runOperations(ctx, m.Operations)
+}
+
+// Apply applies the migration operations to the schema state d.
+func (m Migration) Apply(d *schema.Database) error {
 	for _, op := range m.Operations {
+		if err := op.Apply(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runOperations(ctx context.Context, ops []operations.Operation) error {
+	for _, op := range ops {
 		sql := op.GetSQL()
 
 		_, err := bunny.Exec(ctx, sql)
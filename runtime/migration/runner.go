@@ -2,19 +2,48 @@ package migration
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"time"
 
 	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+	"github.com/sqlbunny/sqlbunny/sqlschema/operations"
 )
 
 const (
 	checkMigrationsTableSQL  = "SELECT count(*) FROM information_schema.tables WHERE table_schema = 'public' AND table_name = 'migrations'"
 	createMigrationsTableSQL = "CREATE TABLE migrations (id text PRIMARY KEY, time timestamptz)"
 	insertMigrationSQL       = "INSERT INTO migrations (id, time) VALUES($1, $2)"
+	deleteMigrationSQL       = "DELETE FROM migrations WHERE id = $1"
 	selectMigrationsSQL      = "SELECT id from migrations"
+	selectMigrationTimesSQL  = "SELECT id, time from migrations"
+	lockSQL                  = "SELECT pg_advisory_lock($1)"
+	unlockSQL                = "SELECT pg_advisory_unlock($1)"
 )
 
+// lockID is the key of the advisory lock held while migrations run.
+const lockID int64 = 0x62756e6e79 // "bunny"
+
+func hasMigrationsTable(ctx context.Context) (bool, error) {
+	var count int64
+	if err := bunny.QueryRow(ctx, checkMigrationsTableSQL).Scan(&count); err != nil {
+		return false, err
+	}
+	return count != 0, nil
+}
+
+func ensureMigrationsTable(ctx context.Context) error {
+	exists, err := hasMigrationsTable(ctx)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	_, err = bunny.Exec(ctx, createMigrationsTableSQL)
+	return err
+}
+
 func getApplied(ctx context.Context) (map[string]struct{}, error) {
 
 	applied := make(map[string]struct{})
@@ -22,6 +51,7 @@ func getApplied(ctx context.Context) (map[string]struct{}, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 	for rows.Next() {
 		var name string
 		if err := rows.Scan(&name); err != nil {
@@ -49,55 +79,145 @@ func (s *Store) ValidateMigrated(ctx context.Context) error {
 	return nil
 }
 
+// Run applies all the pending migrations.
+//
+// An advisory lock is held while migrations run, so concurrent calls to Run,
+// for example from several instances starting at the same time, apply each
+// migration only once. Each migration runs in a transaction together with its
+// insert into the migrations table, unless it contains an operation that can't
+// run in a transaction.
 func (s *Store) Run(ctx context.Context) error {
-	var count int64
-	if err := bunny.QueryRow(ctx, checkMigrationsTableSQL).Scan(&count); err != nil {
-		return err
-	}
-	if count == 0 {
-		if _, err := bunny.Exec(ctx, createMigrationsTableSQL); err != nil {
+	return withLock(ctx, func(ctx context.Context) error {
+		if err := ensureMigrationsTable(ctx); err != nil {
 			return err
 		}
-	}
 
-	applied, err := getApplied(ctx)
-	if err != nil {
-		return err
-	}
-
-	err = s.validateApplied(applied)
-	if err != nil {
-		return err
-	}
+		applied, err := getApplied(ctx)
+		if err != nil {
+			return err
+		}
 
-	rows, err := bunny.Query(ctx, selectMigrationsSQL)
-	if err != nil {
-		return err
-	}
-	for rows.Next() {
-		var name string
-		if err := rows.Scan(&name); err != nil {
+		err = s.validateApplied(applied)
+		if err != nil {
 			return err
 		}
-		applied[name] = struct{}{}
-	}
-	if err := rows.Err(); err != nil {
-		return err
-	}
 
-	heads := s.FindHeads()
-	if len(heads) != 1 {
-		return errors.New("Found multiple migration heads, you must run 'migration merge' first")
-	}
-	head := heads[0]
+		heads := s.FindHeads()
+		if len(heads) != 1 {
+			return errors.New("Found multiple migration heads, you must run 'migration merge' first")
+		}
+		head := heads[0]
+
+		return s.RunMigration(head, applied, func(m *Migration) error {
+			return runAtomic(ctx, m.Operations, func(ctx context.Context) error {
+				if err := runOperations(ctx, m.Operations); err != nil {
+					return err
+				}
+				if _, err := bunny.Exec(ctx, insertMigrationSQL, m.Name, time.Now()); err != nil {
+					return err
+				}
+				return nil
+			})
+		})
+	})
+}
+
+// Rollback undoes the migration named target and all the applied migrations that
+// depend on it, newest first, removing them from the migrations table.
+//
+// Nothing is run if any of the migrations to roll back contains an irreversible operation.
+func (s *Store) Rollback(ctx context.Context, target string) error {
+	return withLock(ctx, func(ctx context.Context) error {
+		applied, err := getApplied(ctx)
+		if err != nil {
+			return err
+		}
 
-	return s.RunMigration(head, applied, func(m *Migration) error {
-		if err := m.Run(ctx); err != nil {
+		err = s.validateApplied(applied)
+		if err != nil {
 			return err
 		}
-		if _, err := bunny.Exec(ctx, insertMigrationSQL, m.Name, time.Now()); err != nil {
+
+		steps, err := s.planRollback(target, applied)
+		if err != nil {
 			return err
 		}
+
+		for _, step := range steps {
+			err := runAtomic(ctx, step.Operations, func(ctx context.Context) error {
+				if err := runOperations(ctx, step.Operations); err != nil {
+					return err
+				}
+				if _, err := bunny.Exec(ctx, deleteMigrationSQL, step.Migration.Name); err != nil {
+					return err
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
 		return nil
 	})
 }
+
+// MarkApplied records the migrations in names as applied, without running them.
+// This is used to adopt an existing database, whose schema already matches the migrations.
+// The migrations table is created if it doesn't exist.
+func MarkApplied(ctx context.Context, names ...string) error {
+	return withLock(ctx, func(ctx context.Context) error {
+		if err := ensureMigrationsTable(ctx); err != nil {
+			return err
+		}
+
+		return bunny.Atomic(ctx, func(ctx context.Context) error {
+			for _, name := range names {
+				if _, err := bunny.Exec(ctx, insertMigrationSQL, name, time.Now()); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+type conner interface {
+	Conn(ctx context.Context) (*sql.Conn, error)
+}
+
+// withLock runs fn holding the migrations advisory lock. Advisory locks belong to
+// a session, so if the database in ctx is a pool a single connection is used for fn.
+func withLock(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if db, ok := bunny.DBFromContext(ctx).(conner); ok {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		ctx = bunny.ContextWithDB(ctx, conn)
+	}
+
+	if _, err := bunny.Exec(ctx, lockSQL, lockID); err != nil {
+		return err
+	}
+	defer func() {
+		// Unlock even if ctx is canceled, so the connection doesn't go back
+		// to the pool holding the lock.
+		_, err2 := bunny.Exec(context.WithoutCancel(ctx), unlockSQL, lockID)
+		if err == nil {
+			err = err2
+		}
+	}()
+
+	return fn(ctx)
+}
+
+// runAtomic runs fn in a transaction, unless some of ops can't run in a transaction.
+func runAtomic(ctx context.Context, ops []operations.Operation, fn func(ctx context.Context) error) error {
+	for _, op := range ops {
+		if !operations.IsTransactional(op) {
+			return fn(ctx)
+		}
+	}
+	return bunny.Atomic(ctx, fn)
+}
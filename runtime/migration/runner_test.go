@@ -0,0 +1,155 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+	"github.com/sqlbunny/sqlbunny/sqlschema/operations"
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+func setupTest(t *testing.T) (context.Context, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return bunny.ContextWithDB(context.Background(), db), mock
+}
+
+func expectRunStart(mock sqlmock.Sqlmock, applied ...string) {
+	mock.ExpectExec(regexp.QuoteMeta(lockSQL)).WithArgs(lockID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(checkMigrationsTableSQL)).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	rows := sqlmock.NewRows([]string{"id"})
+	for _, a := range applied {
+		rows.AddRow(a)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(selectMigrationsSQL)).WillReturnRows(rows)
+}
+
+func TestStoreRunTransactional(t *testing.T) {
+	ctx, mock := setupTest(t)
+
+	s := Store{}
+	s.Register(&Migration{
+		Name: "a",
+	})
+	s.Register(&Migration{
+		Name:         "b",
+		Dependencies: []string{"a"},
+		Operations: []operations.Operation{
+			operations.SQL{SQL: "SELECT 1"},
+		},
+	})
+
+	expectRunStart(mock, "a")
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT 1")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(insertMigrationSQL)).WithArgs("b", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(regexp.QuoteMeta(unlockSQL)).WithArgs(lockID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := s.Run(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStoreRunNonTransactional(t *testing.T) {
+	ctx, mock := setupTest(t)
+
+	s := Store{}
+	s.Register(&Migration{
+		Name: "a",
+		Operations: []operations.Operation{
+			operations.SQL{SQL: "SELECT 1", NonTransactional: true},
+		},
+	})
+
+	expectRunStart(mock)
+	mock.ExpectExec(regexp.QuoteMeta("SELECT 1")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(insertMigrationSQL)).WithArgs("a", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(unlockSQL)).WithArgs(lockID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := s.Run(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStoreRunErrorRollsBack(t *testing.T) {
+	ctx, mock := setupTest(t)
+
+	s := Store{}
+	s.Register(&Migration{
+		Name: "a",
+		Operations: []operations.Operation{
+			operations.SQL{SQL: "SELECT 1"},
+		},
+	})
+
+	expectRunStart(mock)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT 1")).WillReturnError(errTest)
+	mock.ExpectRollback()
+	mock.ExpectExec(regexp.QuoteMeta(unlockSQL)).WithArgs(lockID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := s.Run(ctx); err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+var errTest = errors.New("test error")
+
+func TestStoreStatus(t *testing.T) {
+	ctx, mock := setupTest(t)
+
+	s := Store{}
+	s.Register(&Migration{
+		Name: "a",
+	})
+	s.Register(&Migration{
+		Name:         "b",
+		Dependencies: []string{"a"},
+	})
+	s.Register(&Migration{
+		Name:         "c",
+		Dependencies: []string{"b"},
+	})
+
+	t1 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(checkMigrationsTableSQL)).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(selectMigrationTimesSQL)).WillReturnRows(sqlmock.NewRows([]string{"id", "time"}).
+		AddRow("x", t2).
+		AddRow("a", t1))
+
+	status, err := s.Status(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var got []string
+	for _, st := range status {
+		got = append(got, fmt.Sprintf("%s %s %s", st.Name, st.State, st.AppliedAt.Format("2006-01-02")))
+	}
+	checkEqual(t, "status", got, []string{
+		"a applied 2020-01-01",
+		"x unknown 2020-01-02",
+		"b pending 0001-01-01",
+		"c pending 0001-01-01",
+	})
+}
@@ -0,0 +1,117 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+)
+
+// State is the state of a migration in a database.
+type State int
+
+const (
+	// Pending migrations are in the migration store, but not applied to the database.
+	Pending State = iota
+	// Applied migrations are in the migration store and applied to the database.
+	Applied
+	// Unknown migrations are applied to the database, but not in the migration store.
+	Unknown
+)
+
+func (s State) String() string {
+	switch s {
+	case Pending:
+		return "pending"
+	case Applied:
+		return "applied"
+	case Unknown:
+		return "unknown"
+	}
+	return "invalid"
+}
+
+// Status describes a migration in the migration store or in the database.
+type Status struct {
+	Name  string
+	State State
+	// AppliedAt is the time the migration was applied. It is zero for pending migrations.
+	AppliedAt time.Time
+}
+
+func getAppliedTimes(ctx context.Context) (map[string]time.Time, error) {
+	applied := make(map[string]time.Time)
+
+	exists, err := hasMigrationsTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return applied, nil
+	}
+
+	rows, err := bunny.Query(ctx, selectMigrationTimesSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var t sql.NullTime
+		if err := rows.Scan(&name, &t); err != nil {
+			return nil, err
+		}
+		applied[name] = t.Time
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+// Status returns the status of the migrations in the migration store and in the database.
+// Applied and unknown migrations come first, in the order they were applied. Pending
+// migrations come last, in the order they would be applied.
+func (s *Store) Status(ctx context.Context) ([]Status, error) {
+	times, err := getAppliedTimes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []Status
+	for mn, t := range times {
+		state := Applied
+		if _, ok := s.Migrations[mn]; !ok {
+			state = Unknown
+		}
+		res = append(res, Status{
+			Name:      mn,
+			State:     state,
+			AppliedAt: t,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].AppliedAt.Equal(res[j].AppliedAt) {
+			return res[i].AppliedAt.Before(res[j].AppliedAt)
+		}
+		return res[i].Name < res[j].Name
+	})
+
+	pending := make(map[string]struct{})
+	for mn := range s.Migrations {
+		if _, ok := times[mn]; !ok {
+			pending[mn] = struct{}{}
+		}
+	}
+	for _, m := range s.sortMigrations(pending) {
+		res = append(res, Status{
+			Name:  m.Name,
+			State: Pending,
+		})
+	}
+
+	return res, nil
+}
@@ -2,8 +2,11 @@ package migration
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/sqlbunny/errors"
+	"github.com/sqlbunny/sqlbunny/sqlschema/operations"
+	"github.com/sqlbunny/sqlbunny/sqlschema/schema"
 )
 
 type Store struct {
@@ -143,3 +146,115 @@ func (s *Store) RunMigration(target string, applied map[string]struct{}, fn func
 
 	return nil
 }
+
+// sortMigrations returns the named migrations in an order where every migration
+// comes after its dependencies. Dependencies not in names are skipped. Ties are
+// broken by name, so the order is stable.
+func (s *Store) sortMigrations(names map[string]struct{}) []*Migration {
+	var sorted []string
+	for mn := range names {
+		sorted = append(sorted, mn)
+	}
+	sort.Strings(sorted)
+
+	var res []*Migration
+	done := make(map[string]struct{})
+	var visit func(mn string)
+	visit = func(mn string) {
+		if _, ok := done[mn]; ok {
+			return
+		}
+		if _, ok := names[mn]; !ok {
+			return
+		}
+		done[mn] = struct{}{}
+		m := s.Migrations[mn]
+		for _, dn := range m.Dependencies {
+			visit(dn)
+		}
+		res = append(res, m)
+	}
+	for _, mn := range sorted {
+		visit(mn)
+	}
+	return res
+}
+
+// Replay returns the schema state after applying the named migrations, in dependency order.
+func (s *Store) Replay(names map[string]struct{}) (*schema.Database, error) {
+	d := newDatabase()
+	for mn := range names {
+		if _, ok := s.Migrations[mn]; !ok {
+			return nil, errors.Errorf("Migration '%s' is not in the migration store", mn)
+		}
+	}
+	for _, m := range s.sortMigrations(names) {
+		if err := m.Apply(d); err != nil {
+			return nil, errors.Errorf("Error applying migration '%s': %w", m.Name, err)
+		}
+	}
+	return d, nil
+}
+
+// findDependents returns target plus all the applied migrations that depend on it,
+// directly or indirectly.
+func (s *Store) findDependents(target string, applied map[string]struct{}) map[string]struct{} {
+	reverse := s.calcReverseDeps()
+	res := map[string]struct{}{target: {}}
+	q := []string{target}
+	for len(q) != 0 {
+		mn := q[0]
+		q = q[1:]
+		for _, dn := range reverse[mn] {
+			if _, ok := applied[dn]; !ok {
+				continue
+			}
+			if _, ok := res[dn]; !ok {
+				res[dn] = struct{}{}
+				q = append(q, dn)
+			}
+		}
+	}
+	return res
+}
+
+type rollbackStep struct {
+	Migration  *Migration
+	Operations []operations.Operation
+}
+
+// planRollback calculates the steps to roll back target and all the applied migrations
+// that depend on it. The schema state before each migration is calculated by replaying
+// the applied migrations in dependency order. Steps are returned in the order they must run.
+func (s *Store) planRollback(target string, applied map[string]struct{}) ([]rollbackStep, error) {
+	if _, ok := s.Migrations[target]; !ok {
+		return nil, errors.Errorf("Migration '%s' is not in the migration store", target)
+	}
+	if _, ok := applied[target]; !ok {
+		return nil, errors.Errorf("Migration '%s' is not applied", target)
+	}
+
+	rollback := s.findDependents(target, applied)
+
+	var steps []rollbackStep
+	d := newDatabase()
+	for _, m := range s.sortMigrations(applied) {
+		if _, ok := rollback[m.Name]; ok {
+			ops, err := operations.ReverseAll(d, m.Operations)
+			if err != nil {
+				return nil, errors.Errorf("Migration '%s' can't be rolled back: %w", m.Name, err)
+			}
+			steps = append([]rollbackStep{{Migration: m, Operations: ops}}, steps...)
+		}
+		if err := m.Apply(d); err != nil {
+			return nil, errors.Errorf("Error applying migration '%s': %w", m.Name, err)
+		}
+	}
+	return steps, nil
+}
+
+func newDatabase() *schema.Database {
+	d := schema.NewDatabase()
+	d.Schemas[""] = schema.NewSchema()
+	return d
+}
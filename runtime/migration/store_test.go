@@ -1,6 +1,11 @@
 package migration
 
-import "testing"
+import (
+	"errors"
+	"testing"
+
+	"github.com/sqlbunny/sqlbunny/sqlschema/operations"
+)
 
 func equal(x, y []string) bool {
 	if len(x) != len(y) {
@@ -301,3 +306,121 @@ func TestRun(t *testing.T) {
 	}
 	checkEqualUnsorted(t, "tree3", *r, []string{})
 }
+
+func stepNames(steps []rollbackStep) []string {
+	var res []string
+	for _, s := range steps {
+		res = append(res, s.Migration.Name)
+	}
+	return res
+}
+
+func stepSQL(steps []rollbackStep) []string {
+	var res []string
+	for _, s := range steps {
+		for _, op := range s.Operations {
+			res = append(res, op.GetSQL())
+		}
+	}
+	return res
+}
+
+func TestPlanRollback(t *testing.T) {
+	s := Store{
+		Migrations: map[string]*Migration{
+			"a": &Migration{
+				Name: "a",
+				Operations: []operations.Operation{
+					operations.CreateTable{
+						TableName: "book",
+						Columns: []operations.Column{
+							{Name: "id", Type: "text"},
+						},
+					},
+				},
+			},
+			"b": &Migration{
+				Name:         "b",
+				Dependencies: []string{"a"},
+				Operations: []operations.Operation{
+					operations.AlterTable{
+						TableName: "book",
+						Ops: []operations.AlterTableSuboperation{
+							operations.AlterTableAddColumn{Name: "name", Type: "text"},
+							operations.AlterTableSetDefault{Name: "id", Default: "''"},
+						},
+					},
+				},
+			},
+			"c": &Migration{
+				Name:         "c",
+				Dependencies: []string{"b"},
+				Operations: []operations.Operation{
+					operations.RenameColumn{
+						TableName:     "book",
+						OldColumnName: "name",
+						NewColumnName: "title",
+					},
+				},
+			},
+		},
+	}
+	applied := map[string]struct{}{"a": {}, "b": {}, "c": {}}
+
+	steps, err := s.planRollback("b", applied)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	checkEqual(t, "rollback b names", stepNames(steps), []string{"c", "b"})
+	checkEqual(t, "rollback b sql", stepSQL(steps), []string{
+		"ALTER TABLE \"book\" RENAME COLUMN \"title\" TO \"name\"",
+		"ALTER TABLE \"book\"\n    ALTER COLUMN \"id\" DROP DEFAULT,\n    DROP COLUMN \"name\"",
+	})
+
+	steps, err = s.planRollback("a", applied)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	checkEqual(t, "rollback a names", stepNames(steps), []string{"c", "b", "a"})
+
+	_, err = s.planRollback("c", map[string]struct{}{"a": {}, "b": {}})
+	if err == nil {
+		t.Errorf("expected error rolling back unapplied migration")
+	}
+}
+
+func TestPlanRollbackIrreversible(t *testing.T) {
+	s := Store{
+		Migrations: map[string]*Migration{
+			"a": &Migration{
+				Name: "a",
+				Operations: []operations.Operation{
+					operations.CreateTable{
+						TableName: "book",
+						Columns: []operations.Column{
+							{Name: "id", Type: "text"},
+							{Name: "name", Type: "text"},
+						},
+					},
+				},
+			},
+			"b": &Migration{
+				Name:         "b",
+				Dependencies: []string{"a"},
+				Operations: []operations.Operation{
+					operations.AlterTable{
+						TableName: "book",
+						Ops: []operations.AlterTableSuboperation{
+							operations.AlterTableDropColumn{Name: "name"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	_, err := s.planRollback("a", map[string]struct{}{"a": {}, "b": {}})
+	if !errors.Is(err, operations.ErrIrreversible) {
+		t.Errorf("expected ErrIrreversible, got %v", err)
+	}
+}
@@ -3,20 +3,30 @@ package qm
 import "github.com/sqlbunny/sqlbunny/runtime/queries"
 
 // QueryMod to modify the query object
-type QueryMod func(q *queries.Query)
+type QueryMod interface {
+	Apply(q *queries.Query)
+}
+
+// QueryModFunc is a function implementing QueryMod.
+type QueryModFunc func(q *queries.Query)
+
+// Apply calls f(q).
+func (f QueryModFunc) Apply(q *queries.Query) {
+	f(q)
+}
 
 // Apply the query mods to the Query object
 func Apply(q *queries.Query, mods ...QueryMod) {
 	for _, mod := range mods {
-		mod(q)
+		mod.Apply(q)
 	}
 }
 
 // SQL allows you to execute a plain SQL statement
 func SQL(sql string, args ...any) QueryMod {
-	return func(q *queries.Query) {
+	return QueryModFunc(func(q *queries.Query) {
 		queries.SetSQL(q, sql, args...)
-	}
+	})
 }
 
 // Load allows you to specify foreign key relationships to eager load
@@ -25,16 +35,110 @@ func SQL(sql string, args ...any) QueryMod {
 // Relationship name plurality is important, if your relationship is
 // singular, you need to specify the singular form and vice versa.
 func Load(relationships ...string) QueryMod {
-	return func(q *queries.Query) {
-		queries.AppendLoad(q, relationships...)
+	return loadMod(relationships)
+}
+
+// loadMod is the QueryMod of Load.
+type loadMod []string
+
+func (m loadMod) Apply(q *queries.Query) {
+	queries.AppendLoad(q, m...)
+}
+
+// LoadWith eager loads the relationship, passing mods to the query
+// loading it. The Load and LoadWith mods in mods load relationships of the
+// loaded rows, with their own mods, like:
+//
+//	qm.LoadWith("Books", qm.Where("published"), qm.OrderBy("date"), qm.LoadWith("Reviews"))
+//
+// Sibling relationships are loaded concurrently outside a transaction.
+func LoadWith(relationship string, mods ...QueryMod) QueryMod {
+	node := &queries.LoadNode{Relationship: relationship}
+	for _, mod := range mods {
+		switch mod := mod.(type) {
+		case loadWithMod:
+			node.Children = append(node.Children, mod.node)
+		case loadMod:
+			node.Children = append(node.Children, queries.LoadPathNodes(mod...)...)
+		default:
+			node.Mods = append(node.Mods, mod)
+		}
 	}
+	return loadWithMod{node: node}
+}
+
+// loadWithMod is the QueryMod of LoadWith.
+type loadWithMod struct {
+	node *queries.LoadNode
+}
+
+func (m loadWithMod) Apply(q *queries.Query) {
+	queries.AppendLoadWith(q, m.node)
+}
+
+// ChunkSize sets how many rows Each loads the relationships of at once.
+func ChunkSize(size int) QueryMod {
+	return QueryModFunc(func(q *queries.Query) {
+		queries.SetChunkSize(q, size)
+	})
 }
 
 // InnerJoin on another model
 func InnerJoin(clause string, args ...any) QueryMod {
-	return func(q *queries.Query) {
+	return QueryModFunc(func(q *queries.Query) {
 		queries.AppendInnerJoin(q, clause, args...)
-	}
+	})
+}
+
+// LeftJoin on another model
+func LeftJoin(clause string, args ...any) QueryMod {
+	return QueryModFunc(func(q *queries.Query) {
+		queries.AppendLeftJoin(q, clause, args...)
+	})
+}
+
+// RightJoin on another model
+func RightJoin(clause string, args ...any) QueryMod {
+	return QueryModFunc(func(q *queries.Query) {
+		queries.AppendRightJoin(q, clause, args...)
+	})
+}
+
+// FullJoin on another model
+func FullJoin(clause string, args ...any) QueryMod {
+	return QueryModFunc(func(q *queries.Query) {
+		queries.AppendFullJoin(q, clause, args...)
+	})
+}
+
+// With adds a common table expression named name, which can be used
+// as a model in the rest of the query.
+func With(name string, sub *queries.Query) QueryMod {
+	return QueryModFunc(func(q *queries.Query) {
+		queries.AppendWith(q, name, false, sub)
+	})
+}
+
+// WithRecursive adds a recursive common table expression named name,
+// which can reference itself in sub.
+func WithRecursive(name string, sub *queries.Query) QueryMod {
+	return QueryModFunc(func(q *queries.Query) {
+		queries.AppendWith(q, name, true, sub)
+	})
+}
+
+// Union adds the rows of sub to the results, removing duplicates.
+func Union(sub *queries.Query) QueryMod {
+	return QueryModFunc(func(q *queries.Query) {
+		queries.AppendUnion(q, false, sub)
+	})
+}
+
+// UnionAll adds the rows of sub to the results, keeping duplicates.
+func UnionAll(sub *queries.Query) QueryMod {
+	return QueryModFunc(func(q *queries.Query) {
+		queries.AppendUnion(q, true, sub)
+	})
 }
 
 // Select specific fields opposed to all fields
@@ -43,71 +147,74 @@ func Select[T ~string](fields ...T) QueryMod {
 	for i, f := range fields {
 		s[i] = string(f)
 	}
-	return func(q *queries.Query) {
+	return QueryModFunc(func(q *queries.Query) {
 		queries.AppendSelect(q, s...)
-	}
+	})
 }
 
-// Where allows you to specify a where clause for your statement
+// Where allows you to specify a where clause for your statement.
+// An argument can be a *queries.Query, which replaces its placeholder
+// with the subquery, like qm.Where("id IN ?", sub).
 func Where(clause string, args ...any) QueryMod {
-	return func(q *queries.Query) {
+	return QueryModFunc(func(q *queries.Query) {
 		queries.AppendWhere(q, clause, args...)
-	}
+	})
 }
 
 // WhereIn allows you to specify a "x IN (set)" clause for your where statement
 // Example clauses: "field in ?", "(field1,field2) in ?"
+// The argument can also be a single *queries.Query, for "field in (subquery)".
 func WhereIn(clause string, args ...any) QueryMod {
-	return func(q *queries.Query) {
+	return QueryModFunc(func(q *queries.Query) {
 		queries.AppendIn(q, clause, args...)
-	}
+	})
 }
 
 // GroupBy allows you to specify a group by clause for your statement
 func GroupBy(clause string) QueryMod {
-	return func(q *queries.Query) {
+	return QueryModFunc(func(q *queries.Query) {
 		queries.AppendGroupBy(q, clause)
-	}
+	})
 }
 
 // OrderBy allows you to specify a order by clause for your statement
 func OrderBy(clause string) QueryMod {
-	return func(q *queries.Query) {
+	return QueryModFunc(func(q *queries.Query) {
 		queries.AppendOrderBy(q, clause)
-	}
+	})
 }
 
 // Having allows you to specify a having clause for your statement
 func Having(clause string, args ...any) QueryMod {
-	return func(q *queries.Query) {
+	return QueryModFunc(func(q *queries.Query) {
 		queries.AppendHaving(q, clause, args...)
-	}
+	})
 }
 
 // From allows to specify the model for your statement
 func From(from string) QueryMod {
-	return func(q *queries.Query) {
+	return QueryModFunc(func(q *queries.Query) {
 		queries.AppendFrom(q, from)
-	}
+	})
 }
 
 // Limit the number of returned rows
 func Limit(limit int) QueryMod {
-	return func(q *queries.Query) {
+	return QueryModFunc(func(q *queries.Query) {
 		queries.SetLimit(q, limit)
-	}
+	})
 }
 
 // Offset into the results
 func Offset(offset int) QueryMod {
-	return func(q *queries.Query) {
+	return QueryModFunc(func(q *queries.Query) {
 		queries.SetOffset(q, offset)
-	}
+	})
 }
 
 // For inserts a concurrency locking clause at the end of your statement
 func For(clause string) QueryMod {
-	return func(q *queries.Query) {
+	return QueryModFunc(func(q *queries.Query) {
 		queries.SetFor(q, clause)
-	}
+	})
 }
@@ -0,0 +1,37 @@
+package qm
+
+import (
+	"testing"
+
+	"github.com/sqlbunny/sqlbunny/runtime/queries"
+)
+
+func TestLoadWith(t *testing.T) {
+	t.Parallel()
+
+	applied := 0
+	count := QueryModFunc(func(q *queries.Query) { applied++ })
+
+	q := &queries.Query{}
+	Apply(q, LoadWith("books", count, LoadWith("reviews", count), Load("cover.image")))
+	if applied != 0 {
+		t.Errorf("expected the mods not to be applied, got %d calls", applied)
+	}
+
+	nodes := queries.LoadNodes(q)
+	if len(nodes) != 1 || nodes[0].Relationship != "Books" || len(nodes[0].Mods) != 1 {
+		t.Fatalf("bad nodes: %#v", nodes)
+	}
+	children := nodes[0].Children
+	if len(children) != 2 || children[0].Relationship != "Reviews" || children[1].Relationship != "Cover" {
+		t.Fatalf("bad children: %#v", children)
+	}
+	if len(children[0].Mods) != 1 || len(children[1].Children) != 1 || children[1].Children[0].Relationship != "Image" {
+		t.Errorf("bad grandchildren: %#v", children)
+	}
+
+	nodes[0].Mods[0].Apply(q)
+	if applied != 1 {
+		t.Errorf("expected the mod to be kept, got %d calls", applied)
+	}
+}
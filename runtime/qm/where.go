@@ -0,0 +1,145 @@
+package qm
+
+import (
+	"database/sql/driver"
+	"strings"
+)
+
+// WhereField builds typed where clauses on a column. The generated
+// ModelWhere variables have one for each column of the model.
+type WhereField[T any] struct {
+	column string
+}
+
+// NewWhereField returns a WhereField for column, which must be quoted.
+func NewWhereField[T any](column string) WhereField[T] {
+	return WhereField[T]{column: column}
+}
+
+// EQ matches the rows where the column is equal to x.
+func (w WhereField[T]) EQ(x T) QueryMod {
+	return Where(w.column+" = ?", x)
+}
+
+// NEQ matches the rows where the column is not equal to x.
+func (w WhereField[T]) NEQ(x T) QueryMod {
+	return Where(w.column+" <> ?", x)
+}
+
+// LT matches the rows where the column is less than x.
+func (w WhereField[T]) LT(x T) QueryMod {
+	return Where(w.column+" < ?", x)
+}
+
+// LTE matches the rows where the column is less than or equal to x.
+func (w WhereField[T]) LTE(x T) QueryMod {
+	return Where(w.column+" <= ?", x)
+}
+
+// GT matches the rows where the column is greater than x.
+func (w WhereField[T]) GT(x T) QueryMod {
+	return Where(w.column+" > ?", x)
+}
+
+// GTE matches the rows where the column is greater than or equal to x.
+func (w WhereField[T]) GTE(x T) QueryMod {
+	return Where(w.column+" >= ?", x)
+}
+
+// In matches the rows where the column is equal to any of xs.
+// No rows match if xs is empty.
+func (w WhereField[T]) In(xs ...T) QueryMod {
+	if len(xs) == 0 {
+		return Where("FALSE")
+	}
+	return Where(w.column+" IN ("+placeholders(len(xs))+")", anys(xs)...)
+}
+
+// NIn matches the rows where the column is not equal to any of xs.
+// All rows match if xs is empty.
+func (w WhereField[T]) NIn(xs ...T) QueryMod {
+	if len(xs) == 0 {
+		return Where("TRUE")
+	}
+	return Where(w.column+" NOT IN ("+placeholders(len(xs))+")", anys(xs)...)
+}
+
+// WhereNullField builds typed where clauses on a nullable column.
+type WhereNullField[T any] struct {
+	WhereField[T]
+}
+
+// NewWhereNullField returns a WhereNullField for column, which must be quoted.
+func NewWhereNullField[T any](column string) WhereNullField[T] {
+	return WhereNullField[T]{WhereField: NewWhereField[T](column)}
+}
+
+// EQ matches the rows where the column is equal to x. If x is null, it
+// matches the rows where the column is null.
+func (w WhereNullField[T]) EQ(x T) QueryMod {
+	if isNull(x) {
+		return w.IsNull()
+	}
+	return w.WhereField.EQ(x)
+}
+
+// NEQ matches the rows where the column is not equal to x. If x is null, it
+// matches the rows where the column is not null.
+func (w WhereNullField[T]) NEQ(x T) QueryMod {
+	if isNull(x) {
+		return w.IsNotNull()
+	}
+	return w.WhereField.NEQ(x)
+}
+
+// IsNull matches the rows where the column is null.
+func (w WhereNullField[T]) IsNull() QueryMod {
+	return Where(w.column + " IS NULL")
+}
+
+// IsNotNull matches the rows where the column is not null.
+func (w WhereNullField[T]) IsNotNull() QueryMod {
+	return Where(w.column + " IS NOT NULL")
+}
+
+// WhereNullStruct builds where clauses on whether a nullable struct field is null,
+// using the boolean column storing it.
+type WhereNullStruct struct {
+	column string
+}
+
+// NewWhereNullStruct returns a WhereNullStruct for column, which must be quoted.
+func NewWhereNullStruct(column string) WhereNullStruct {
+	return WhereNullStruct{column: column}
+}
+
+// IsNull matches the rows where the struct is null.
+func (w WhereNullStruct) IsNull() QueryMod {
+	return Where(w.column + " IS NOT TRUE")
+}
+
+// IsNotNull matches the rows where the struct is not null.
+func (w WhereNullStruct) IsNotNull() QueryMod {
+	return Where(w.column + " IS TRUE")
+}
+
+func isNull(x any) bool {
+	v, ok := x.(driver.Valuer)
+	if !ok {
+		return false
+	}
+	val, err := v.Value()
+	return err == nil && val == nil
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+func anys[T any](xs []T) []any {
+	res := make([]any, len(xs))
+	for i, x := range xs {
+		res[i] = x
+	}
+	return res
+}
@@ -0,0 +1,141 @@
+package qm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+	"github.com/sqlbunny/sqlbunny/runtime/queries"
+	"github.com/sqlbunny/sqlbunny/types/null"
+)
+
+var errRecorded = errors.New("recorded")
+
+// recordDB records the query run on it instead of running it.
+type recordDB struct {
+	query string
+	args  []any
+}
+
+func (db *recordDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return nil, errRecorded
+}
+
+func (db *recordDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	db.query, db.args = query, args
+	return nil, errRecorded
+}
+
+func (db *recordDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return nil
+}
+
+// whereQuery returns the SQL and arguments of a query on "author" with mod.
+func whereQuery(t *testing.T, mod QueryMod) (string, []any) {
+	t.Helper()
+
+	db := &recordDB{}
+	q := &queries.Query{}
+	queries.SetDialect(q, &queries.Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true})
+	Apply(q, From(`"author"`), mod)
+	if _, err := q.Query(bunny.ContextWithDB(context.Background(), db)); !errors.Is(err, errRecorded) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return db.query, db.args
+}
+
+func TestWhereField(t *testing.T) {
+	t.Parallel()
+
+	name := NewWhereField[string](`"author"."name"`)
+	age := NewWhereField[int](`"author"."age"`)
+
+	tests := []struct {
+		mod    QueryMod
+		expect string
+		args   []any
+	}{
+		{name.EQ("a"), `SELECT * FROM "author" WHERE ("author"."name" = $1);`, []any{"a"}},
+		{name.NEQ("a"), `SELECT * FROM "author" WHERE ("author"."name" <> $1);`, []any{"a"}},
+		{age.LT(1), `SELECT * FROM "author" WHERE ("author"."age" < $1);`, []any{1}},
+		{age.LTE(1), `SELECT * FROM "author" WHERE ("author"."age" <= $1);`, []any{1}},
+		{age.GT(1), `SELECT * FROM "author" WHERE ("author"."age" > $1);`, []any{1}},
+		{age.GTE(1), `SELECT * FROM "author" WHERE ("author"."age" >= $1);`, []any{1}},
+		{name.In("a", "b"), `SELECT * FROM "author" WHERE ("author"."name" IN ($1,$2));`, []any{"a", "b"}},
+		{name.In(), `SELECT * FROM "author" WHERE (FALSE);`, nil},
+		{name.NIn("a", "b"), `SELECT * FROM "author" WHERE ("author"."name" NOT IN ($1,$2));`, []any{"a", "b"}},
+		{name.NIn(), `SELECT * FROM "author" WHERE (TRUE);`, nil},
+	}
+
+	for i, test := range tests {
+		query, args := whereQuery(t, test.mod)
+		if query != test.expect {
+			t.Errorf("%d) want: %s, got: %s", i, test.expect, query)
+		}
+		if !reflect.DeepEqual(args, test.args) {
+			t.Errorf("%d) want args %v, got %v", i, test.args, args)
+		}
+	}
+}
+
+func TestWhereNullField(t *testing.T) {
+	t.Parallel()
+
+	bio := NewWhereNullField[null.String](`"author"."bio"`)
+
+	tests := []struct {
+		mod    QueryMod
+		expect string
+		args   []any
+	}{
+		{bio.EQ(null.StringFrom("a")), `SELECT * FROM "author" WHERE ("author"."bio" = $1);`, []any{null.StringFrom("a")}},
+		{bio.EQ(null.String{}), `SELECT * FROM "author" WHERE ("author"."bio" IS NULL);`, nil},
+		{bio.NEQ(null.StringFrom("a")), `SELECT * FROM "author" WHERE ("author"."bio" <> $1);`, []any{null.StringFrom("a")}},
+		{bio.NEQ(null.String{}), `SELECT * FROM "author" WHERE ("author"."bio" IS NOT NULL);`, nil},
+		{bio.IsNull(), `SELECT * FROM "author" WHERE ("author"."bio" IS NULL);`, nil},
+		{bio.IsNotNull(), `SELECT * FROM "author" WHERE ("author"."bio" IS NOT NULL);`, nil},
+		{bio.GT(null.StringFrom("a")), `SELECT * FROM "author" WHERE ("author"."bio" > $1);`, []any{null.StringFrom("a")}},
+		{bio.In(null.StringFrom("a")), `SELECT * FROM "author" WHERE ("author"."bio" IN ($1));`, []any{null.StringFrom("a")}},
+	}
+
+	for i, test := range tests {
+		query, args := whereQuery(t, test.mod)
+		if query != test.expect {
+			t.Errorf("%d) want: %s, got: %s", i, test.expect, query)
+		}
+		if !reflect.DeepEqual(args, test.args) {
+			t.Errorf("%d) want args %v, got %v", i, test.args, args)
+		}
+	}
+}
+
+func TestWhereNullStruct(t *testing.T) {
+	t.Parallel()
+
+	price := NewWhereNullStruct(`"author"."price"`)
+	amount := NewWhereNullField[null.Int64](`"author"."price__amount"`)
+
+	tests := []struct {
+		mod    QueryMod
+		expect string
+		args   []any
+	}{
+		{price.IsNull(), `SELECT * FROM "author" WHERE ("author"."price" IS NOT TRUE);`, nil},
+		{price.IsNotNull(), `SELECT * FROM "author" WHERE ("author"."price" IS TRUE);`, nil},
+		{amount.LT(null.Int64From(5)), `SELECT * FROM "author" WHERE ("author"."price__amount" < $1);`, []any{null.Int64From(5)}},
+		{amount.EQ(null.Int64{}), `SELECT * FROM "author" WHERE ("author"."price__amount" IS NULL);`, nil},
+	}
+
+	for i, test := range tests {
+		query, args := whereQuery(t, test.mod)
+		if query != test.expect {
+			t.Errorf("%d) want: %s, got: %s", i, test.expect, query)
+		}
+		if !reflect.DeepEqual(args, test.args) {
+			t.Errorf("%d) want args %v, got %v", i, test.args, args)
+		}
+	}
+}
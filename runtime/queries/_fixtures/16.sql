@@ -0,0 +1 @@
+SELECT "c".* FROM cats c LEFT JOIN dogs d on d.cat_id = c.id RIGHT JOIN owners o on o.id = c.owner_id and o.age > $1 FULL JOIN toys t on t.cat_id = c.id;
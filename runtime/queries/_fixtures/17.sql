@@ -0,0 +1 @@
+SELECT * FROM "cats" WHERE (name = $1) AND (id IN (SELECT "cat_id" FROM "dogs" WHERE (age > $2)) AND color = $3) AND (id IN (SELECT "cat_id" FROM "dogs" WHERE (age > $4))) AND "owner_id" IN ($5,$6);
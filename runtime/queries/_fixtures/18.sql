@@ -0,0 +1 @@
+WITH RECURSIVE tree AS (SELECT * FROM "categories" WHERE (id = $1) UNION ALL SELECT c.* FROM categories c INNER JOIN tree t on c.parent_id = t.id WHERE (c.depth < $2)) SELECT * FROM "tree" WHERE (name <> $3) UNION SELECT * FROM "categories" WHERE (root = $4) ORDER BY name;
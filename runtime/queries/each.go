@@ -0,0 +1,89 @@
+package queries
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/sqlbunny/errors"
+)
+
+// DefaultChunkSize is the number of rows Each loads the relationships of
+// at once, if not set with SetChunkSize.
+const DefaultChunkSize = 100
+
+// SetChunkSize on the query.
+func SetChunkSize(q *Query, size int) {
+	q.chunkSize = size
+}
+
+// Each executes the query and calls fn for each row, scanned into a new T,
+// without keeping all the rows in memory. If the query loads relationships,
+// the rows are read in chunks and the relationships are loaded for each chunk
+// before calling fn on its rows. The errors returned by fn are returned as is.
+//
+// The rows are read while fn runs. Inside a transaction, the connection is
+// busy with them, so fn can't run queries and relationships can't be loaded.
+func Each[T any](ctx context.Context, q *Query, fn func(*T) error) error {
+	structType := reflect.TypeOf((*T)(nil)).Elem()
+
+	rows, err := q.Query(ctx)
+	if err != nil {
+		return errors.Errorf("each failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return errors.Errorf("each failed to get field names: %w", err)
+	}
+	mapping, err := cachedBindMapping(structType, cols)
+	if err != nil {
+		return err
+	}
+
+	nodes := LoadNodes(q)
+	chunkSize := 1
+	if len(nodes) != 0 {
+		chunkSize = q.chunkSize
+		if chunkSize <= 0 {
+			chunkSize = DefaultChunkSize
+		}
+	}
+
+	chunk := make([]*T, 0, chunkSize)
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		if len(nodes) != 0 {
+			if err := eagerLoadNodes(ctx, nodes, &chunk, kindPtrSliceStruct); err != nil {
+				return err
+			}
+		}
+		for _, o := range chunk {
+			if err := fn(o); err != nil {
+				return err
+			}
+		}
+		chunk = chunk[:0]
+		return nil
+	}
+
+	for rows.Next() {
+		o := new(T)
+		if err := rows.Scan(PtrsFromMapping(reflect.Indirect(reflect.ValueOf(o)), mapping)...); err != nil {
+			return errors.Errorf("failed to bind pointers to obj: %w", err)
+		}
+		chunk = append(chunk, o)
+		if len(chunk) == chunkSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return flush()
+}
@@ -0,0 +1,113 @@
+package queries
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+type testEach struct {
+	ID int `bunny:"id"`
+	R  *testEachR
+	L  testEachL
+}
+
+type testEachR struct {
+	Child *testEachChild
+}
+
+type testEachL struct{}
+
+type testEachChild struct {
+	ID int
+	R  *struct{}
+	L  struct{}
+}
+
+var testEachChunks []int
+
+func (testEachL) LoadChild(_ context.Context, slice []*testEach) error {
+	testEachChunks = append(testEachChunks, len(slice))
+	for _, o := range slice {
+		o.R = &testEachR{Child: &testEachChild{ID: o.ID * 10}}
+	}
+	return nil
+}
+
+func setupEachTest(t *testing.T, ids ...int) (context.Context, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	rows := sqlmock.NewRows([]string{"id"})
+	for _, id := range ids {
+		rows.AddRow(id)
+	}
+	mock.ExpectQuery(`SELECT \* FROM "things";`).WillReturnRows(rows)
+	return bunny.ContextWithDB(context.Background(), db), mock
+}
+
+func TestEach(t *testing.T) {
+	ctx, _ := setupEachTest(t, 1, 2, 3)
+
+	q := &Query{dialect: &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true}, from: []string{"things"}}
+	var got []int
+	err := Each(ctx, q, func(o *testEach) error {
+		got = append(got, o.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Errorf("Expected ids 1, 2, 3, got %v", got)
+	}
+}
+
+func TestEach_Error(t *testing.T) {
+	ctx, _ := setupEachTest(t, 1, 2, 3)
+
+	errStop := errors.New("stop")
+	q := &Query{dialect: &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true}, from: []string{"things"}}
+	var got []int
+	err := Each(ctx, q, func(o *testEach) error {
+		got = append(got, o.ID)
+		return errStop
+	})
+	if err != errStop {
+		t.Errorf("Expected the error returned by fn, got %v", err)
+	}
+	if !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("Expected id 1, got %v", got)
+	}
+}
+
+func TestEach_LoadChunks(t *testing.T) {
+	ctx, _ := setupEachTest(t, 1, 2, 3, 4, 5)
+	testEachChunks = nil
+
+	q := &Query{dialect: &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true}, from: []string{"things"}}
+	SetLoad(q, "Child")
+	SetChunkSize(q, 2)
+	var got []int
+	err := Each(ctx, q, func(o *testEach) error {
+		got = append(got, o.R.Child.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []int{10, 20, 30, 40, 50}) {
+		t.Errorf("Unexpected loaded children %v", got)
+	}
+	if !reflect.DeepEqual(testEachChunks, []int{2, 2, 1}) {
+		t.Errorf("Unexpected chunk sizes %v", testEachChunks)
+	}
+}
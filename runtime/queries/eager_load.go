@@ -2,41 +2,87 @@ package queries
 
 import (
 	"context"
+	"database/sql"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/sqlbunny/errors"
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
 	"github.com/sqlbunny/sqlbunny/runtime/strmangle"
 )
 
-type loadRelationshipState struct {
-	ctx    context.Context
-	loaded map[string]struct{}
-	toLoad []string
+// Mod modifies a query. The QueryMods of the qm package implement it.
+type Mod interface {
+	Apply(q *Query)
 }
 
-func (l loadRelationshipState) hasLoaded(depth int) bool {
-	_, ok := l.loaded[l.buildKey(depth)]
-	return ok
+// LoadNode is a relationship to eager load. Its Mods are passed to the
+// generated LoadRelationship method, and its Children are loaded from the
+// rows it loads.
+type LoadNode struct {
+	Relationship string
+	Mods         []Mod
+	Children     []*LoadNode
+}
+
+// AppendLoadWith on the query.
+func AppendLoadWith(q *Query, nodes ...*LoadNode) {
+	q.loadWith = append(q.loadWith, nodes...)
 }
 
-func (l loadRelationshipState) setLoaded(depth int) {
-	l.loaded[l.buildKey(depth)] = struct{}{}
+// LoadNodes returns the relationships the query eager loads, both the dotted
+// ones of SetLoad and AppendLoad and the ones of AppendLoadWith, as a tree.
+// Sibling nodes of the same relationship are merged, concatenating their mods,
+// so that each relationship is loaded once.
+func LoadNodes(q *Query) []*LoadNode {
+	return loadTree(q.load, q.loadWith)
 }
 
-func (l loadRelationshipState) buildKey(depth int) string {
-	buf := strmangle.GetBuffer()
+// LoadPathNodes returns the dotted relationships, in the format of SetLoad,
+// as a tree.
+func LoadPathNodes(paths ...string) []*LoadNode {
+	return loadTree(paths, nil)
+}
 
-	for i, piece := range l.toLoad[:depth+1] {
-		if i != 0 {
-			buf.WriteByte('.')
+func loadTree(toLoad []string, nodes []*LoadNode) []*LoadNode {
+	all := make([]*LoadNode, 0, len(toLoad)+len(nodes))
+	for _, path := range toLoad {
+		var node *LoadNode
+		pieces := strings.Split(path, ".")
+		for i := len(pieces) - 1; i >= 0; i-- {
+			parent := &LoadNode{Relationship: pieces[i]}
+			if node != nil {
+				parent.Children = []*LoadNode{node}
+			}
+			node = parent
 		}
-		buf.WriteString(piece)
+		all = append(all, node)
 	}
+	all = append(all, nodes...)
+	return mergeLoadNodes(all)
+}
 
-	str := buf.String()
-	strmangle.PutBuffer(buf)
-	return str
+// mergeLoadNodes merges the nodes of the same relationship into new nodes,
+// keeping the order in which the relationships first appear.
+func mergeLoadNodes(nodes []*LoadNode) []*LoadNode {
+	var res []*LoadNode
+	byName := make(map[string]*LoadNode)
+	for _, n := range nodes {
+		name := strmangle.TitleCase(n.Relationship)
+		m, ok := byName[name]
+		if !ok {
+			m = &LoadNode{Relationship: name}
+			byName[name] = m
+			res = append(res, m)
+		}
+		m.Mods = append(m.Mods, n.Mods...)
+		m.Children = append(m.Children, n.Children...)
+	}
+	for _, m := range res {
+		m.Children = mergeLoadNodes(m.Children)
+	}
+	return res
 }
 
 // eagerLoad loads all of the model's relationships
@@ -47,11 +93,12 @@ func (l loadRelationshipState) buildKey(depth int) string {
 // *[]*struct or *struct
 // bkind should reflect what kind of thing it is above
 func eagerLoad(ctx context.Context, toLoad []string, obj any, bkind bindKind) error {
-	state := loadRelationshipState{
-		ctx:    ctx,
-		loaded: map[string]struct{}{},
-	}
+	return eagerLoadNodes(ctx, loadTree(toLoad, nil), obj, bkind)
+}
 
+// eagerLoadNodes loads the relationships of the tree of nodes into obj,
+// which is like in eagerLoad.
+func eagerLoadNodes(ctx context.Context, nodes []*LoadNode, obj any, bkind bindKind) error {
 	val := reflect.ValueOf(obj)
 	if bkind == kindStruct {
 		r := reflect.MakeSlice(reflect.SliceOf(val.Type()), 1, 1)
@@ -61,28 +108,19 @@ func eagerLoad(ctx context.Context, toLoad []string, obj any, bkind bindKind) er
 		val = val.Elem()
 	}
 
-	for _, toLoad := range toLoad {
-		state.toLoad = strings.Split(toLoad, ".")
-		for i := range state.toLoad {
-			state.toLoad[i] = strmangle.TitleCase(state.toLoad[i])
-		}
-		if err := state.loadRelationships(0, val); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return loadNodes(ctx, nodes, val)
 }
 
-// loadRelationships dynamically calls the template generated eager load
+// loadNodes dynamically calls the template generated eager load
 // functions of the form:
 //
-//	func (l ModelL) LoadRelationshipName(ctx context.Context, slice []*Model) error
+//	func (l ModelL) LoadRelationshipName(ctx context.Context, slice []*Model, mods ...qm.QueryMod) error
 //
 // The arguments to this function are:
 //   - l is not used, and it is always passed the zero value.
 //   - ctx is used to perform additional queries that might be required for loading the relationships.
 //   - slice is the slice of model instances, always of the type []*Model.
+//   - mods are the Mods of the node.
 //
 // We start with a normal select before eager loading anything: select * from a;
 // Then we start eager loading things, it can be represented by a DAG
@@ -96,44 +134,95 @@ func eagerLoad(ctx context.Context, toLoad []string, obj any, bkind bindKind) er
 // That's to say that we descend the graph of relationships, and at each level
 // we gather all the things up we want to load into, load them, and then move
 // to the next level of the graph.
-func (l loadRelationshipState) loadRelationships(depth int, loadingFrom reflect.Value) error {
-	if loadingFrom.Len() == 0 {
+//
+// When the database of ctx is a connection pool, and not a transaction, the
+// sibling relationships are loaded concurrently.
+func loadNodes(ctx context.Context, nodes []*LoadNode, loadingFrom reflect.Value) error {
+	if loadingFrom.Len() == 0 || len(nodes) == 0 {
 		return nil
 	}
 
-	if !l.hasLoaded(depth) {
-		if err := l.callLoadFunction(depth, loadingFrom); err != nil {
+	if len(nodes) == 1 || !canLoadConcurrently(ctx) {
+		for _, node := range nodes {
+			if err := loadNode(ctx, node, loadingFrom); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// The load functions set R if it's nil, which would race.
+	if err := initRelationships(loadingFrom); err != nil {
+		return err
+	}
+
+	errs := make([]error, len(nodes))
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = loadNode(ctx, node, loadingFrom)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
 			return err
 		}
 	}
+	return nil
+}
 
-	// Check if we can stop
-	if depth+1 >= len(l.toLoad) {
-		return nil
+// loadNode loads the relationship of node into loadingFrom, and its children
+// into the loaded rows.
+func loadNode(ctx context.Context, node *LoadNode, loadingFrom reflect.Value) error {
+	if err := callLoadFunction(ctx, node, loadingFrom); err != nil {
+		return err
 	}
 
-	// *[]*struct -> []*struct
-	// *struct -> struct
-	loadingFrom = reflect.Indirect(loadingFrom)
+	if len(node.Children) == 0 {
+		return nil
+	}
 
 	// Collect eagerly loaded things to send into next eager load call
-	slice, err := collectLoaded(l.toLoad[depth], loadingFrom)
+	slice, err := collectLoaded(node.Relationship, loadingFrom)
 	if err != nil {
 		return err
 	}
 
-	// If we could collect nothing we're done
-	if slice.Len() == 0 {
-		return nil
+	return loadNodes(ctx, node.Children, slice)
+}
+
+// canLoadConcurrently reports whether the database of ctx can run queries
+// concurrently, which a transaction can't.
+func canLoadConcurrently(ctx context.Context) bool {
+	_, ok := ctx.Value(bunny.ContextDBKey).(*sql.DB)
+	return ok
+}
+
+// initRelationships sets the relationship struct of the models in slice
+// that don't have one.
+func initRelationships(slice reflect.Value) error {
+	f, ok := slice.Type().Elem().Elem().FieldByName(relationshipStructName)
+	if !ok {
+		return errors.New("relationship struct was not found")
 	}
 
-	return l.loadRelationships(depth+1, slice)
+	for i := 0; i < slice.Len(); i++ {
+		r := slice.Index(i).Elem().FieldByIndex(f.Index)
+		if r.IsNil() {
+			r.Set(reflect.New(f.Type.Elem()))
+		}
+	}
+	return nil
 }
 
 // callLoadFunction finds the loader struct, finds the method that we need
 // to call and calls it.
-func (l loadRelationshipState) callLoadFunction(depth int, loadingFrom reflect.Value) error {
-	current := l.toLoad[depth]
+func callLoadFunction(ctx context.Context, node *LoadNode, loadingFrom reflect.Value) error {
+	current := node.Relationship
 	sliceType := loadingFrom.Type()
 	modelType := sliceType.Elem().Elem()
 	ln, found := modelType.FieldByName(loaderStructName)
@@ -150,16 +239,29 @@ func (l loadRelationshipState) callLoadFunction(depth int, loadingFrom reflect.V
 
 	methodArgs := []reflect.Value{
 		reflect.Zero(ln.Type),
-		reflect.ValueOf(l.ctx),
+		reflect.ValueOf(ctx),
 		loadingFrom,
 	}
 
-	ret := loadMethod.Func.Call(methodArgs)
+	var ret []reflect.Value
+	if loadMethod.Type.IsVariadic() {
+		// The mods are converted to the qm.QueryMod type of the method.
+		modsType := loadMethod.Type.In(loadMethod.Type.NumIn() - 1)
+		mods := reflect.MakeSlice(modsType, len(node.Mods), len(node.Mods))
+		for i, mod := range node.Mods {
+			mods.Index(i).Set(reflect.ValueOf(mod).Convert(modsType.Elem()))
+		}
+		ret = loadMethod.Func.CallSlice(append(methodArgs, mods))
+	} else {
+		if len(node.Mods) != 0 {
+			return errors.Errorf("%s%s method for eager loading doesn't take query mods", loadMethodPrefix, current)
+		}
+		ret = loadMethod.Func.Call(methodArgs)
+	}
 	if intf := ret[0].Interface(); intf != nil {
 		return errors.Errorf("failed to eager load %s: %w", current, intf.(error))
 	}
 
-	l.setLoaded(depth)
 	return nil
 }
 
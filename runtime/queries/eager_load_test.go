@@ -3,7 +3,11 @@ package queries
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
+
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
 )
 
 var testEagerCounters struct {
@@ -329,3 +333,129 @@ func checkNestedMany(ns []*testEagerNested) {
 		panic(fmt.Sprintf("ns[1] had wrong id: %d", ns[1].ID))
 	}
 }
+
+// testEagerMod is like qm.QueryMod.
+type testEagerMod interface {
+	Apply(q *Query)
+}
+
+type testEagerModFunc func(q *Query)
+
+func (f testEagerModFunc) Apply(q *Query) {
+	f(q)
+}
+
+type testEagerTree struct {
+	ID int
+	R  *testEagerTreeR
+	L  testEagerTreeL
+}
+
+type testEagerTreeR struct {
+	Left  []*testEagerTree
+	Right *testEagerTree
+}
+
+type testEagerTreeL struct{}
+
+// testEagerTreeLimits records the limit set by the mods of each load call.
+var testEagerTreeLimits sync.Map
+
+func (testEagerTreeL) LoadLeft(_ context.Context, slice []*testEagerTree, mods ...testEagerMod) error {
+	q := &Query{}
+	for _, mod := range mods {
+		mod.Apply(q)
+	}
+	for _, o := range slice {
+		if o.R == nil {
+			o.R = &testEagerTreeR{}
+		}
+		testEagerTreeLimits.Store(o.ID*10+1, q.limit)
+		o.R.Left = []*testEagerTree{{ID: o.ID*10 + 1}, {ID: o.ID*10 + 2}}
+	}
+	return nil
+}
+
+func (testEagerTreeL) LoadRight(_ context.Context, slice []*testEagerTree, mods ...testEagerMod) error {
+	q := &Query{}
+	for _, mod := range mods {
+		mod.Apply(q)
+	}
+	for _, o := range slice {
+		if o.R == nil {
+			o.R = &testEagerTreeR{}
+		}
+		testEagerTreeLimits.Store(o.ID*10+3, q.limit)
+		o.R.Right = &testEagerTree{ID: o.ID*10 + 3}
+	}
+	return nil
+}
+
+func TestLoadNodesMerge(t *testing.T) {
+	t.Parallel()
+
+	limit := testEagerModFunc(func(q *Query) { SetLimit(q, 5) })
+	q := &Query{}
+	SetLoad(q, "left.right", "left")
+	AppendLoadWith(q, &LoadNode{Relationship: "Left", Mods: []Mod{limit}, Children: []*LoadNode{{Relationship: "Left"}}})
+
+	nodes := LoadNodes(q)
+	if len(nodes) != 1 || nodes[0].Relationship != "Left" || len(nodes[0].Mods) != 1 {
+		t.Fatalf("bad nodes: %#v", nodes)
+	}
+	children := nodes[0].Children
+	if len(children) != 2 || children[0].Relationship != "Right" || children[1].Relationship != "Left" {
+		t.Fatalf("bad children: %#v", children)
+	}
+}
+
+func TestEagerLoadNodes(t *testing.T) {
+	t.Parallel()
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, ctx := range []context.Context{context.Background(), bunny.ContextWithDB(context.Background(), db)} {
+		testEagerTreeLimits = sync.Map{}
+
+		nodes := []*LoadNode{
+			{
+				Relationship: "Left",
+				Mods:         []Mod{testEagerModFunc(func(q *Query) { SetLimit(q, 1) })},
+				Children: []*LoadNode{
+					{Relationship: "Right", Mods: []Mod{testEagerModFunc(func(q *Query) { SetLimit(q, 2) })}},
+					{Relationship: "Left"},
+				},
+			},
+			{Relationship: "Right"},
+		}
+		obj := []*testEagerTree{{ID: 1}, {ID: 2}}
+		if err := eagerLoadNodes(ctx, nodes, &obj, kindPtrSliceStruct); err != nil {
+			t.Fatal(err)
+		}
+
+		if obj[1].R.Right.ID != 23 || obj[1].R.Left[1].R.Right.ID != 223 || len(obj[0].R.Left[0].R.Left) != 2 {
+			t.Fatalf("relationships not loaded: %#v", obj[1].R)
+		}
+		want := map[int]int{11: 1, 13: 0, 113: 2, 111: 0}
+		for id, limit := range want {
+			got, _ := testEagerTreeLimits.Load(id)
+			if got != limit {
+				t.Errorf("limit of %d: got %v, want %d", id, got, limit)
+			}
+		}
+	}
+}
+
+func TestEagerLoadModsNotVariadic(t *testing.T) {
+	t.Parallel()
+
+	nodes := []*LoadNode{{Relationship: "ChildOne", Mods: []Mod{testEagerModFunc(func(q *Query) {})}}}
+	obj := &testEager{}
+	if err := eagerLoadNodes(context.Background(), nodes, obj, kindStruct); err == nil {
+		t.Error("expected an error")
+	}
+}
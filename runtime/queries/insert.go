@@ -0,0 +1,118 @@
+package queries
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/sqlbunny/sqlbunny/runtime/strmangle"
+)
+
+// MaxInsertParams is the maximum number of parameters in a statement
+// supported by the Postgres protocol.
+const MaxInsertParams = 65535
+
+// InsertColumns returns the columns to insert, leaving out the columns with a
+// database default whose field has the zero value, and the columns left out.
+func InsertColumns(value reflect.Value, mapping map[string]MappedField, cols []string, defaults []string) ([]string, []string) {
+	if len(defaults) == 0 {
+		return cols, nil
+	}
+
+	var wl, ret []string
+	for _, c := range cols {
+		if strmangle.SetInclude(c, defaults) && IsZeroFromMapping(value, mapping[c]) {
+			ret = append(ret, c)
+		} else {
+			wl = append(wl, c)
+		}
+	}
+	return wl, ret
+}
+
+// InsertGroup is a set of rows inserted with the same columns.
+type InsertGroup struct {
+	// Columns are the inserted columns.
+	Columns []string
+	// Returning are the columns read back from the database.
+	Returning []string
+	Values    []reflect.Value
+}
+
+// GroupInsertColumns groups values by the columns to insert, as chosen by
+// InsertColumns, keeping the order of the values. With a whitelist, all the
+// values are in a single group.
+func GroupInsertColumns(values []reflect.Value, mapping map[string]MappedField, cols []string, defaults []string, whitelist []string) []*InsertGroup {
+	if len(whitelist) != 0 {
+		g := &InsertGroup{
+			Columns:   whitelist,
+			Returning: strmangle.SetComplement(defaults, whitelist),
+			Values:    values,
+		}
+		return []*InsertGroup{g}
+	}
+
+	var groups []*InsertGroup
+	byKey := make(map[string]*InsertGroup)
+	for _, value := range values {
+		wl, ret := InsertColumns(value, mapping, cols, defaults)
+		key := strings.Join(wl, ",")
+		g, ok := byKey[key]
+		if !ok {
+			g = &InsertGroup{Columns: wl, Returning: ret}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.Values = append(g.Values, value)
+	}
+	return groups
+}
+
+// Batches splits the values of g into batches of as many rows as fit in a
+// statement with MaxInsertParams parameters. Without columns, the rows are
+// inserted with DEFAULT VALUES, one per statement.
+func (g *InsertGroup) Batches() [][]reflect.Value {
+	size := 1
+	if len(g.Columns) != 0 {
+		size = MaxInsertParams / len(g.Columns)
+	}
+
+	var res [][]reflect.Value
+	for values := g.Values; len(values) != 0; {
+		batch := values[:min(size, len(values))]
+		values = values[len(batch):]
+		res = append(res, batch)
+	}
+	return res
+}
+
+// BuildInsertAllQuery builds the statement inserting n rows of the wl columns
+// into table, returning the ret columns.
+func BuildInsertAllQuery(dia Dialect, table string, wl []string, ret []string, n int) string {
+	wl = strmangle.IdentQuoteSlice(dia.LQ, dia.RQ, wl)
+	ret = strmangle.IdentQuoteSlice(dia.LQ, dia.RQ, ret)
+
+	buf := strmangle.GetBuffer()
+	defer strmangle.PutBuffer(buf)
+
+	buf.WriteString("INSERT INTO ")
+	buf.WriteString(table)
+	if len(wl) == 0 {
+		buf.WriteString(" DEFAULT VALUES")
+	} else {
+		fmt.Fprintf(buf, " (%s) VALUES ", strings.Join(wl, ","))
+		for i := 0; i < n; i++ {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('(')
+			buf.WriteString(strmangle.Placeholders(dia.IndexPlaceholders, len(wl), i*len(wl)+1, 1))
+			buf.WriteByte(')')
+		}
+	}
+	if len(ret) != 0 {
+		buf.WriteString(" RETURNING ")
+		buf.WriteString(strings.Join(ret, ","))
+	}
+	return buf.String()
+}
@@ -0,0 +1,137 @@
+package queries
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type insertTestRow struct {
+	ID    int    `bunny:"id"`
+	Name  string `bunny:"name"`
+	Count int    `bunny:"count"`
+}
+
+func insertTestValues(rows ...*insertTestRow) []reflect.Value {
+	values := make([]reflect.Value, len(rows))
+	for i, r := range rows {
+		values[i] = reflect.Indirect(reflect.ValueOf(r))
+	}
+	return values
+}
+
+func TestGroupInsertColumns(t *testing.T) {
+	t.Parallel()
+
+	mapping := MakeStructMapping(reflect.TypeOf(insertTestRow{}))
+	cols := []string{"id", "name", "count"}
+	defaults := []string{"id", "count"}
+
+	values := insertTestValues(
+		&insertTestRow{Name: "a"},
+		&insertTestRow{Name: "b", Count: 1},
+		&insertTestRow{Name: "c"},
+		&insertTestRow{ID: 1, Name: "d", Count: 1},
+	)
+
+	groups := GroupInsertColumns(values, mapping, cols, defaults, nil)
+	want := []struct {
+		columns   []string
+		returning []string
+		values    []reflect.Value
+	}{
+		{[]string{"name"}, []string{"id", "count"}, []reflect.Value{values[0], values[2]}},
+		{[]string{"name", "count"}, []string{"id"}, []reflect.Value{values[1]}},
+		{[]string{"id", "name", "count"}, nil, []reflect.Value{values[3]}},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("want %d groups, got %d", len(want), len(groups))
+	}
+	for i, g := range groups {
+		if !reflect.DeepEqual(g.Columns, want[i].columns) || !reflect.DeepEqual(g.Returning, want[i].returning) {
+			t.Errorf("%d) bad columns: %v, returning: %v", i, g.Columns, g.Returning)
+		}
+		if len(g.Values) != len(want[i].values) {
+			t.Errorf("%d) want %d values, got %d", i, len(want[i].values), len(g.Values))
+			continue
+		}
+		for j := range g.Values {
+			if g.Values[j].Addr().Pointer() != want[i].values[j].Addr().Pointer() {
+				t.Errorf("%d) value %d is out of order", i, j)
+			}
+		}
+	}
+
+	groups = GroupInsertColumns(values, mapping, cols, defaults, []string{"name"})
+	if len(groups) != 1 || len(groups[0].Values) != len(values) {
+		t.Fatalf("expected a single group with a whitelist, got %d", len(groups))
+	}
+	if !reflect.DeepEqual(groups[0].Returning, []string{"id", "count"}) {
+		t.Errorf("bad returning: %v", groups[0].Returning)
+	}
+}
+
+func TestInsertGroupBatches(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		columns int
+		rows    int
+		sizes   []int
+	}{
+		{columns: 3, rows: MaxInsertParams / 3, sizes: []int{MaxInsertParams / 3}},
+		{columns: 3, rows: MaxInsertParams/3 + 1, sizes: []int{MaxInsertParams / 3, 1}},
+		{columns: 2, rows: MaxInsertParams, sizes: []int{MaxInsertParams / 2, MaxInsertParams / 2, 1}},
+		{columns: 0, rows: 3, sizes: []int{1, 1, 1}},
+		{columns: 1, rows: 0, sizes: nil},
+	}
+
+	for i, test := range tests {
+		g := &InsertGroup{
+			Columns: make([]string, test.columns),
+			Values:  make([]reflect.Value, test.rows),
+		}
+		var sizes []int
+		for _, batch := range g.Batches() {
+			if len(batch)*test.columns > MaxInsertParams {
+				t.Errorf("%d) batch of %d rows exceeds the parameter limit", i, len(batch))
+			}
+			sizes = append(sizes, len(batch))
+		}
+		if !reflect.DeepEqual(sizes, test.sizes) {
+			t.Errorf("%d) want batches %v, got %v", i, test.sizes, sizes)
+		}
+	}
+}
+
+func TestBuildInsertAllQuery(t *testing.T) {
+	t.Parallel()
+
+	dia := Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true}
+
+	tests := []struct {
+		wl     []string
+		ret    []string
+		n      int
+		expect string
+	}{
+		{[]string{"a", "b"}, nil, 2, `INSERT INTO "t" ("a","b") VALUES ($1,$2),($3,$4)`},
+		{[]string{"a"}, []string{"id"}, 1, `INSERT INTO "t" ("a") VALUES ($1) RETURNING "id"`},
+		{[]string{"a"}, []string{"id"}, 3, `INSERT INTO "t" ("a") VALUES ($1),($2),($3) RETURNING "id"`},
+		{nil, []string{"id", "b"}, 1, `INSERT INTO "t" DEFAULT VALUES RETURNING "id","b"`},
+	}
+
+	for i, test := range tests {
+		got := BuildInsertAllQuery(dia, `"t"`, test.wl, test.ret, test.n)
+		if got != test.expect {
+			t.Errorf("%d) want: %s, got: %s", i, test.expect, got)
+		}
+	}
+
+	wl := []string{"a", "b", "c"}
+	n := MaxInsertParams / len(wl)
+	got := BuildInsertAllQuery(dia, `"t"`, wl, nil, n)
+	if !strings.HasSuffix(got, "($65533,$65534,$65535)") {
+		t.Errorf("bad last row: %s", got[len(got)-30:])
+	}
+}
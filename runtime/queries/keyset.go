@@ -0,0 +1,98 @@
+package queries
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sqlbunny/errors"
+	"github.com/sqlbunny/sqlbunny/runtime/strmangle"
+)
+
+// KeysetColumns returns columns followed by the primary key columns that are
+// not in columns, so that the order of rows in keyset pagination is unique.
+func KeysetColumns(columns []string, primaryKey []string) []string {
+	res := append([]string(nil), columns...)
+	for _, c := range primaryKey {
+		if !strmangle.SetInclude(c, res) {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// SetKeyset orders q by the columns of table, replacing its order by, and if
+// values is not nil, selects the rows after values with a row comparison.
+// The order is ascending, or descending if desc is set. The columns must not
+// be nullable, as row comparisons with NULL never match.
+func SetKeyset(q *Query, table string, columns []string, values []any, desc bool) {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = strmangle.IdentQuote(q.dialect.LQ, q.dialect.RQ, table+"."+c)
+	}
+
+	op, dir := ">", "ASC"
+	if desc {
+		op, dir = "<", "DESC"
+	}
+
+	if values != nil {
+		clause := fmt.Sprintf("(%s) %s (%s)", strings.Join(quoted, ", "), op, strmangle.Placeholders(false, len(values), 1, 1))
+		AppendWhere(q, clause, values...)
+	}
+
+	q.orderBy = nil
+	for _, c := range quoted {
+		AppendOrderBy(q, c+" "+dir)
+	}
+}
+
+type cursor struct {
+	Before bool              `json:"b,omitempty"`
+	Values []json.RawMessage `json:"v"`
+}
+
+// EncodeCursor returns an opaque cursor for keyset pagination holding the
+// column values of a row. If before is set, the cursor points to the rows
+// before it, otherwise to the rows after it.
+func EncodeCursor(before bool, values []any) (string, error) {
+	c := cursor{Before: before, Values: make([]json.RawMessage, len(values))}
+	for i, v := range values {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", errors.Errorf("unable to encode cursor value %d: %w", i, err)
+		}
+		c.Values[i] = b
+	}
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// DecodeCursor decodes the column values of a cursor made by EncodeCursor
+// into ptrs, and returns whether it points to the rows before them.
+func DecodeCursor(s string, ptrs []any) (bool, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return false, errors.Errorf("invalid cursor: %w", err)
+	}
+
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return false, errors.Errorf("invalid cursor: %w", err)
+	}
+	if len(c.Values) != len(ptrs) {
+		return false, errors.Errorf("invalid cursor: it has %d values, expected %d", len(c.Values), len(ptrs))
+	}
+
+	for i, v := range c.Values {
+		if err := json.Unmarshal(v, ptrs[i]); err != nil {
+			return false, errors.Errorf("invalid cursor value %d: %w", i, err)
+		}
+	}
+	return c.Before, nil
+}
@@ -0,0 +1,63 @@
+package queries
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestKeysetColumns(t *testing.T) {
+	t.Parallel()
+
+	got := KeysetColumns([]string{"created_at", "id"}, []string{"id", "tenant_id"})
+	expect := []string{"created_at", "id", "tenant_id"}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expected %v, got %v", expect, got)
+	}
+}
+
+func TestSetKeyset(t *testing.T) {
+	t.Parallel()
+
+	q := &Query{dialect: &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true}, from: []string{"book"}}
+	AppendWhere(q, "author_id = ?", "a")
+	AppendOrderBy(q, "title")
+	SetKeyset(q, "book", []string{"title", "id"}, []any{"t", "b"}, true)
+
+	out, args := buildQuery(q)
+	expect := `SELECT * FROM "book" WHERE (author_id = $1) AND (("book"."title", "book"."id") < ($2,$3)) ORDER BY "book"."title" DESC, "book"."id" DESC;`
+	if out != expect {
+		t.Errorf("Expected %s, got %s", expect, out)
+	}
+	if !reflect.DeepEqual(args, []any{"a", "t", "b"}) {
+		t.Errorf("Unexpected args %v", args)
+	}
+}
+
+func TestCursor(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	s, err := EncodeCursor(true, []any{at, "id1", int64(3)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gotAt time.Time
+	var gotID string
+	var gotN int64
+	before, err := DecodeCursor(s, []any{&gotAt, &gotID, &gotN})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !before || !gotAt.Equal(at) || gotID != "id1" || gotN != 3 {
+		t.Errorf("Unexpected decoded cursor %v %v %v %v", before, gotAt, gotID, gotN)
+	}
+
+	if _, err := DecodeCursor(s, []any{&gotAt}); err == nil {
+		t.Error("Expected an error decoding a cursor with the wrong number of values")
+	}
+	if _, err := DecodeCursor("not a cursor", []any{&gotAt}); err == nil {
+		t.Error("Expected an error decoding an invalid cursor")
+	}
+}
@@ -16,13 +16,17 @@ const (
 	JoinOuterLeft
 	JoinOuterRight
 	JoinNatural
+	JoinOuterFull
 )
 
 // Query holds the state for the built up query
 type Query struct {
 	dialect    *Dialect
 	rawSQL     rawSQL
+	with       []with
 	load       []string
+	loadWith   []*LoadNode
+	chunkSize  int
 	delete     bool
 	update     map[string]any
 	selectCols []string
@@ -34,6 +38,7 @@ type Query struct {
 	groupBy    []string
 	orderBy    []string
 	having     []having
+	unions     []union
 	limit      int
 	offset     int
 	forlock    string
@@ -71,6 +76,19 @@ type having struct {
 	args   []any
 }
 
+type with struct {
+	name      string
+	recursive bool
+	clause    string
+	args      []any
+}
+
+type union struct {
+	all    bool
+	clause string
+	args   []any
+}
+
 type rawSQL struct {
 	sql  string
 	args []any
@@ -110,6 +128,17 @@ func (q *Query) Query(ctx context.Context) (*sql.Rows, error) {
 	return bunny.Query(ctx, qs, args...)
 }
 
+// ReadReplica returns the context running q on a replica with
+// bunny.ReadReplica, for the read finishers. Raw queries, queries with a WITH
+// clause, which may write rows, and queries writing or locking rows stay on
+// the primary.
+func ReadReplica(ctx context.Context, q *Query) context.Context {
+	if q.rawSQL.sql != "" || len(q.with) != 0 || q.delete || q.update != nil || q.forlock != "" {
+		return ctx
+	}
+	return bunny.ReadReplica(ctx)
+}
+
 // SetDialect on the query.
 func SetDialect(q *Query, dialect *Dialect) {
 	q.dialect = dialect
@@ -187,24 +216,65 @@ func SetFrom(q *Query, from ...string) {
 
 // AppendInnerJoin on the query.
 func AppendInnerJoin(q *Query, clause string, args ...any) {
+	clause, args = expandSubqueries(q, clause, args)
 	q.joins = append(q.joins, join{clause: clause, kind: JoinInner, args: args})
 }
 
+// AppendLeftJoin on the query.
+func AppendLeftJoin(q *Query, clause string, args ...any) {
+	clause, args = expandSubqueries(q, clause, args)
+	q.joins = append(q.joins, join{clause: clause, kind: JoinOuterLeft, args: args})
+}
+
+// AppendRightJoin on the query.
+func AppendRightJoin(q *Query, clause string, args ...any) {
+	clause, args = expandSubqueries(q, clause, args)
+	q.joins = append(q.joins, join{clause: clause, kind: JoinOuterRight, args: args})
+}
+
+// AppendFullJoin on the query.
+func AppendFullJoin(q *Query, clause string, args ...any) {
+	clause, args = expandSubqueries(q, clause, args)
+	q.joins = append(q.joins, join{clause: clause, kind: JoinOuterFull, args: args})
+}
+
 // AppendHaving on the query.
 func AppendHaving(q *Query, clause string, args ...any) {
+	clause, args = expandSubqueries(q, clause, args)
 	q.having = append(q.having, having{clause: clause, args: args})
 }
 
 // AppendWhere on the query.
 func AppendWhere(q *Query, clause string, args ...any) {
+	clause, args = expandSubqueries(q, clause, args)
 	q.where = append(q.where, where{clause: clause, args: args})
 }
 
-// AppendIn on the query.
+// AppendIn on the query. If the only argument is a subquery, the clause is
+// appended as a where clause, like "id IN (SELECT ...)".
 func AppendIn(q *Query, clause string, args ...any) {
+	if len(args) == 1 {
+		if _, ok := args[0].(*Query); ok {
+			AppendWhere(q, clause, args...)
+			return
+		}
+	}
 	q.in = append(q.in, in{clause: clause, args: args})
 }
 
+// AppendWith adds a common table expression named name to the query.
+// If any of them is recursive, the WITH clause is WITH RECURSIVE.
+func AppendWith(q *Query, name string, recursive bool, sub *Query) {
+	clause, args := buildSubquery(q, sub)
+	q.with = append(q.with, with{name: name, recursive: recursive, clause: clause, args: args})
+}
+
+// AppendUnion on the query. If all is set, duplicate rows are kept (UNION ALL).
+func AppendUnion(q *Query, all bool, sub *Query) {
+	clause, args := buildSubquery(q, sub)
+	q.unions = append(q.unions, union{all: all, clause: clause, args: args})
+}
+
 // AppendGroupBy on the query.
 func AppendGroupBy(q *Query, clause string) {
 	q.groupBy = append(q.groupBy, clause)
@@ -44,6 +44,8 @@ func buildSelectQuery(q *Query) (*bytes.Buffer, []any) {
 	buf := strmangle.GetBuffer()
 	var args []any
 
+	writeWith(q, buf, &args)
+
 	buf.WriteString("SELECT ")
 
 	if q.dialect.UseTopClause {
@@ -82,10 +84,11 @@ func buildSelectQuery(q *Query) (*bytes.Buffer, []any) {
 		argsLen := len(args)
 		joinBuf := strmangle.GetBuffer()
 		for _, j := range q.joins {
-			if j.kind != JoinInner {
-				panic("only inner joins are supported")
+			kind, ok := joinKeywords[j.kind]
+			if !ok {
+				panic("unsupported join kind")
 			}
-			fmt.Fprintf(joinBuf, " INNER JOIN %s", j.clause)
+			fmt.Fprintf(joinBuf, " %s %s", kind, j.clause)
 			args = append(args, j.args...)
 		}
 		var resp string
@@ -257,12 +260,16 @@ func BuildUpsertQueryPostgres(dia Dialect, modelName string, updateOnConflict bo
 		fields,
 	)
 
+	if len(conflict) != 0 {
+		buf.WriteByte('(')
+		buf.WriteString(strings.Join(conflict, ", "))
+		buf.WriteString(") ")
+	}
+
 	if !updateOnConflict || len(update) == 0 {
 		buf.WriteString("DO NOTHING")
 	} else {
-		buf.WriteByte('(')
-		buf.WriteString(strings.Join(conflict, ", "))
-		buf.WriteString(") DO UPDATE SET ")
+		buf.WriteString("DO UPDATE SET ")
 
 		for i, v := range update {
 			if i != 0 {
@@ -352,6 +359,28 @@ func writeModifiers(q *Query, buf *bytes.Buffer, args *[]any) {
 		strmangle.PutBuffer(havingBuf)
 	}
 
+	if len(q.unions) != 0 {
+		argsLen := len(*args)
+		unionBuf := strmangle.GetBuffer()
+		for _, u := range q.unions {
+			if u.all {
+				unionBuf.WriteString(" UNION ALL ")
+			} else {
+				unionBuf.WriteString(" UNION ")
+			}
+			unionBuf.WriteString(u.clause)
+			*args = append(*args, u.args...)
+		}
+		var resp string
+		if q.dialect.IndexPlaceholders {
+			resp, _ = convertQuestionMarks(unionBuf.String(), argsLen+1)
+		} else {
+			resp = unionBuf.String()
+		}
+		buf.WriteString(resp)
+		strmangle.PutBuffer(unionBuf)
+	}
+
 	if len(q.orderBy) != 0 {
 		buf.WriteString(" ORDER BY ")
 		buf.WriteString(strings.Join(q.orderBy, ", "))
@@ -394,6 +423,119 @@ func writeModifiers(q *Query, buf *bytes.Buffer, args *[]any) {
 	}
 }
 
+var joinKeywords = map[joinKind]string{
+	JoinInner:      "INNER JOIN",
+	JoinOuterLeft:  "LEFT JOIN",
+	JoinOuterRight: "RIGHT JOIN",
+	JoinOuterFull:  "FULL JOIN",
+}
+
+// writeWith writes the WITH clause of the common table expressions of q.
+// It must be written first, because its placeholders are numbered from 1.
+func writeWith(q *Query, buf *bytes.Buffer, args *[]any) {
+	if len(q.with) == 0 {
+		return
+	}
+
+	withBuf := strmangle.GetBuffer()
+	defer strmangle.PutBuffer(withBuf)
+
+	withBuf.WriteString("WITH ")
+	for _, w := range q.with {
+		if w.recursive {
+			withBuf.WriteString("RECURSIVE ")
+			break
+		}
+	}
+	for i, w := range q.with {
+		if i != 0 {
+			withBuf.WriteString(", ")
+		}
+		fmt.Fprintf(withBuf, "%s AS (%s)", w.name, w.clause)
+		*args = append(*args, w.args...)
+	}
+	withBuf.WriteByte(' ')
+
+	if q.dialect.IndexPlaceholders {
+		resp, _ := convertQuestionMarks(withBuf.String(), 1)
+		buf.WriteString(resp)
+	} else {
+		buf.WriteString(withBuf.String())
+	}
+}
+
+// buildSubquery builds sub to be nested in q. The placeholders of the result
+// are ? so they're numbered along with the ones of q when q is built.
+// Subqueries made with raw SQL must use ? placeholders too.
+func buildSubquery(q *Query, sub *Query) (string, []any) {
+	if len(sub.rawSQL.sql) != 0 {
+		return strings.TrimSuffix(sub.rawSQL.sql, ";"), sub.rawSQL.args
+	}
+
+	var dialect Dialect
+	if sub.dialect != nil {
+		dialect = *sub.dialect
+	} else if q.dialect != nil {
+		dialect = *q.dialect
+	}
+	dialect.IndexPlaceholders = false
+
+	// Build a copy, so the query cached in sub keeps its own placeholders.
+	sq := *sub
+	sq.dialect = &dialect
+	buf, args := buildSelectQuery(&sq)
+	defer strmangle.PutBuffer(buf)
+
+	return strings.TrimSuffix(buf.String(), ";"), args
+}
+
+// expandSubqueries replaces the placeholders in clause of the args that are
+// a *Query with the subquery in parentheses, and its args.
+func expandSubqueries(q *Query, clause string, args []any) (string, []any) {
+	hasSubquery := false
+	for _, arg := range args {
+		if _, ok := arg.(*Query); ok {
+			hasSubquery = true
+			break
+		}
+	}
+	if !hasSubquery {
+		return clause, args
+	}
+
+	buf := strmangle.GetBuffer()
+	defer strmangle.PutBuffer(buf)
+	var newArgs []any
+
+	argIndex := 0
+	for i := 0; i < len(clause); i++ {
+		if clause[i] == '\\' && i+1 < len(clause) && clause[i+1] == '?' {
+			buf.WriteString(`\?`)
+			i++
+			continue
+		}
+		if clause[i] != '?' || argIndex >= len(args) {
+			buf.WriteByte(clause[i])
+			continue
+		}
+
+		if sub, ok := args[argIndex].(*Query); ok {
+			subClause, subArgs := buildSubquery(q, sub)
+			buf.WriteByte('(')
+			buf.WriteString(subClause)
+			buf.WriteByte(')')
+			newArgs = append(newArgs, subArgs...)
+		} else {
+			buf.WriteByte('?')
+			newArgs = append(newArgs, args[argIndex])
+		}
+		argIndex++
+	}
+	newArgs = append(newArgs, args[argIndex:]...)
+
+	return buf.String(), newArgs
+}
+
 func writeStars(q *Query) []string {
 	cols := make([]string, len(q.from))
 	for i, f := range q.from {
@@ -93,6 +93,13 @@ func TestBuildQuery(t *testing.T) {
 		{&Query{from: []string{"cats c"}, joins: []join{{JoinInner, "dogs d on d.cat_id = cats.id", nil}}}, nil},
 		{&Query{from: []string{"cats as c"}, joins: []join{{JoinInner, "dogs d on d.cat_id = cats.id", nil}}}, nil},
 		{&Query{from: []string{"cats as c", "dogs as d"}, joins: []join{{JoinInner, "dogs d on d.cat_id = cats.id", nil}}}, nil},
+		{&Query{from: []string{"cats c"}, joins: []join{
+			{JoinOuterLeft, "dogs d on d.cat_id = c.id", nil},
+			{JoinOuterRight, "owners o on o.id = c.owner_id and o.age > ?", []any{18}},
+			{JoinOuterFull, "toys t on t.cat_id = c.id", nil},
+		}}, []any{18}},
+		{subqueryTestQuery(), []any{"a", 3, "black", 3, 1, 2}},
+		{withTestQuery(), []any{1, 5, "x", true}},
 	}
 
 	for i, test := range tests {
@@ -124,6 +131,41 @@ func TestBuildQuery(t *testing.T) {
 	}
 }
 
+func subqueryTestQuery() *Query {
+	dialect := &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true}
+
+	sub := &Query{dialect: dialect, from: []string{"dogs"}, selectCols: []string{"cat_id"}}
+	AppendWhere(sub, "age > ?", 3)
+
+	q := &Query{dialect: dialect, from: []string{"cats"}}
+	AppendWhere(q, "name = ?", "a")
+	AppendWhere(q, "id IN ? AND color = ?", sub, "black")
+	AppendIn(q, "id IN ?", sub)
+	AppendIn(q, "owner_id IN ?", 1, 2)
+	return q
+}
+
+func withTestQuery() *Query {
+	dialect := &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true}
+
+	base := &Query{dialect: dialect, from: []string{"categories"}}
+	AppendWhere(base, "id = ?", 1)
+	rec := &Query{dialect: dialect, from: []string{"categories c"}, selectCols: []string{"c.*"}}
+	AppendInnerJoin(rec, "tree t on c.parent_id = t.id")
+	AppendWhere(rec, "c.depth < ?", 5)
+	AppendUnion(base, true, rec)
+
+	other := &Query{dialect: dialect, from: []string{"categories"}}
+	AppendWhere(other, "root = ?", true)
+
+	q := &Query{dialect: dialect, from: []string{"tree"}}
+	AppendWith(q, "tree", true, base)
+	AppendWhere(q, "name <> ?", "x")
+	AppendUnion(q, false, other)
+	AppendOrderBy(q, "name")
+	return q
+}
+
 func TestWriteStars(t *testing.T) {
 	t.Parallel()
 
@@ -486,3 +528,44 @@ func TestWriteAsStatements(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildUpsertQueryPostgres(t *testing.T) {
+	t.Parallel()
+
+	dia := Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true}
+
+	tests := []struct {
+		update   bool
+		ret      []string
+		upd      []string
+		conflict []string
+		expect   string
+	}{
+		{
+			update:   true,
+			ret:      []string{"seq"},
+			upd:      []string{"title"},
+			conflict: []string{"id"},
+			expect:   `INSERT INTO "book" ("id", "title") VALUES ($1,$2) ON CONFLICT ("id") DO UPDATE SET "title" = EXCLUDED."title" RETURNING "seq"`,
+		},
+		{
+			conflict: []string{"author_id", "title"},
+			expect:   `INSERT INTO "book" ("id", "title") VALUES ($1,$2) ON CONFLICT ("author_id", "title") DO NOTHING`,
+		},
+		{
+			update:   true,
+			conflict: []string{"id"},
+			expect:   `INSERT INTO "book" ("id", "title") VALUES ($1,$2) ON CONFLICT ("id") DO NOTHING`,
+		},
+		{
+			expect: `INSERT INTO "book" ("id", "title") VALUES ($1,$2) ON CONFLICT DO NOTHING`,
+		},
+	}
+
+	for i, test := range tests {
+		got := BuildUpsertQueryPostgres(dia, `"book"`, test.update, test.ret, test.upd, test.conflict, []string{"id", "title"})
+		if got != test.expect {
+			t.Errorf("%d) want: %s, got: %s", i, test.expect, got)
+		}
+	}
+}
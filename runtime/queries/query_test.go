@@ -1,6 +1,7 @@
 package queries
 
 import (
+	"context"
 	"reflect"
 	"testing"
 )
@@ -344,3 +345,31 @@ func TestAppendInnerJoin(t *testing.T) {
 		t.Errorf("Got invalid innerJoin on string: %#v", q.joins)
 	}
 }
+
+func TestReadReplica(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	q := &Query{}
+	SetFrom(q, "t")
+	if ReadReplica(ctx, q) == ctx {
+		t.Error("expected a select to read on a replica")
+	}
+
+	primary := []func(q *Query){
+		func(q *Query) { SetSQL(q, "DELETE FROM t RETURNING *") },
+		func(q *Query) { SetDelete(q) },
+		func(q *Query) { SetUpdate(q, map[string]any{"a": 1}) },
+		func(q *Query) { SetFor(q, "UPDATE") },
+		func(q *Query) { AppendWith(q, "d", false, Raw("DELETE FROM t RETURNING *")) },
+	}
+	for i, mod := range primary {
+		q := &Query{}
+		SetFrom(q, "t")
+		mod(q)
+		if ReadReplica(ctx, q) != ctx {
+			t.Errorf("%d) expected the query to stay on the primary", i)
+		}
+	}
+}
@@ -86,8 +86,8 @@ func (q *Query) Bind(ctx context.Context, obj any) error {
 		return res
 	}
 
-	if len(q.load) != 0 {
-		return eagerLoad(ctx, q.load, obj, bkind)
+	if nodes := LoadNodes(q); len(nodes) != 0 {
+		return eagerLoadNodes(ctx, nodes, obj, bkind)
 	}
 
 	return nil
@@ -160,33 +160,9 @@ func bind(rows *sql.Rows, obj any, structType, sliceType reflect.Type, bkind bin
 		ptrSlice = reflect.Indirect(reflect.ValueOf(obj))
 	}
 
-	var strMapping map[string]MappedField
-	var sok bool
-	var mapping []MappedField
-	var ok bool
-
-	mapKey := makeBindingMapKey(structType, cols)
-	mut.RLock()
-	mapping, ok = bindingMaps[mapKey]
-	if !ok {
-		if strMapping, sok = structMaps[structType]; !sok {
-			strMapping = MakeStructMapping(structType)
-		}
-	}
-	mut.RUnlock()
-
-	if !ok {
-		mapping, err = BindMapping(structType, strMapping, cols)
-		if err != nil {
-			return err
-		}
-
-		mut.Lock()
-		if !sok {
-			structMaps[structType] = strMapping
-		}
-		bindingMaps[mapKey] = mapping
-		mut.Unlock()
+	mapping, err := cachedBindMapping(structType, cols)
+	if err != nil {
+		return err
 	}
 
 	var oneStruct reflect.Value
@@ -240,6 +216,42 @@ func bind(rows *sql.Rows, obj any, structType, sliceType reflect.Type, bkind bin
 	return nil
 }
 
+// cachedBindMapping returns the mapping of cols to the fields of structType,
+// caching it for the next queries.
+func cachedBindMapping(structType reflect.Type, cols []string) ([]MappedField, error) {
+	var strMapping map[string]MappedField
+	var sok bool
+	var mapping []MappedField
+	var ok bool
+
+	mapKey := makeBindingMapKey(structType, cols)
+	mut.RLock()
+	mapping, ok = bindingMaps[mapKey]
+	if !ok {
+		if strMapping, sok = structMaps[structType]; !sok {
+			strMapping = MakeStructMapping(structType)
+		}
+	}
+	mut.RUnlock()
+
+	if !ok {
+		var err error
+		mapping, err = BindMapping(structType, strMapping, cols)
+		if err != nil {
+			return nil, err
+		}
+
+		mut.Lock()
+		if !sok {
+			structMaps[structType] = strMapping
+		}
+		bindingMaps[mapKey] = mapping
+		mut.Unlock()
+	}
+
+	return mapping, nil
+}
+
 // BindMapping creates a mapping that helps look up the pointer for the
 // field given.
 func BindMapping(typ reflect.Type, mapping map[string]MappedField, cols []string) ([]MappedField, error) {
@@ -287,6 +299,17 @@ func ValuesFromMapping(val reflect.Value, mapping []MappedField) []any {
 	return ptrs
 }
 
+// IsZeroFromMapping expects to be passed an addressable struct and a mapping
+// of where to find a field. It reports whether the field has the zero value
+// of its type.
+func IsZeroFromMapping(val reflect.Value, mapping MappedField) bool {
+	if mapping.Path == 0 {
+		return false
+	}
+	v := reflect.ValueOf(ptrFromMapping(val, mapping, false))
+	return !v.IsValid() || v.IsZero()
+}
+
 type ignoreNullScan struct {
 	dest any
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/sqlbunny/sqlbunny/runtime/bunny"
 	"gopkg.in/DATA-DOG/go-sqlmock.v2"
@@ -349,6 +350,44 @@ func TestValuesFromMapping(t *testing.T) {
 	}
 }
 
+func TestIsZeroFromMapping(t *testing.T) {
+	t.Parallel()
+
+	type Nested struct {
+		Int  int
+		Time time.Time
+	}
+	type Model struct {
+		Int    int
+		String string
+		Nested Nested
+	}
+
+	val := &Model{
+		Int: 5,
+		Nested: Nested{
+			Time: time.Now(),
+		},
+	}
+	value := reflect.Indirect(reflect.ValueOf(val))
+
+	tests := []struct {
+		mapping MappedField
+		zero    bool
+	}{
+		{testMakeMapping(0), false},
+		{testMakeMapping(1), true},
+		{testMakeMapping(2, 0), true},
+		{testMakeMapping(2, 1), false},
+		{MappedField{}, false},
+	}
+	for i, test := range tests {
+		if got := IsZeroFromMapping(value, test.mapping); got != test.zero {
+			t.Errorf("%d: expected %v, got %v", i, test.zero, got)
+		}
+	}
+}
+
 func TestPtrsFromMapping(t *testing.T) {
 	t.Parallel()
 
@@ -7,6 +7,14 @@ type Field struct {
 	Type     Type
 	Nullable bool
 
+	// RenamedFrom is the previous name of the field, if it was renamed.
+	RenamedFrom string
+
+	// Default is the SQL expression for the column default, generated by the
+	// database when the field is not inserted. If empty, non-nullable columns
+	// default to the zero value of the type.
+	Default string
+
 	Tags Tags
 
 	Extendable
@@ -1,5 +1,7 @@
 package schema
 
+import "github.com/sqlbunny/sqlbunny/sqlschema/schema"
+
 // PrimaryKey represents a primary key in a database
 type PrimaryKey struct {
 	Fields []Path
@@ -17,9 +19,19 @@ type Unique struct {
 	Fields []Path
 }
 
+// Check represents a check constraint in a database
+type Check struct {
+	Name     string
+	Expr     string
+	NotValid bool // Add the constraint NOT VALID, without checking the existing rows.
+}
+
 // ForeignKey represents a foreign key constraint in a database
 type ForeignKey struct {
 	LocalFields   []Path
 	ForeignModel  string
 	ForeignFields []Path
+	OnDelete      schema.ForeignKeyAction
+	OnUpdate      schema.ForeignKeyAction
+	Deferrable    bool
 }
@@ -7,13 +7,22 @@ type Model struct {
 	Name   string
 	Fields []*Field
 
+	// RenamedFrom is the previous name of the model, if it was renamed.
+	RenamedFrom string
+
 	PrimaryKey  *PrimaryKey
 	Indexes     []*Index
 	Uniques     []*Unique
 	ForeignKeys []*ForeignKey
+	Checks      []*Check
 
 	IsJoinModel bool
 
+	// ReturnAll makes Insert read back all the columns with RETURNING instead
+	// of only the defaulted ones, and Update read back all the columns instead
+	// of none, for tables whose rows are modified by triggers.
+	ReturnAll bool
+
 	Relationships []*Relationship
 
 	Table *schema.Table
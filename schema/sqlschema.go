@@ -4,6 +4,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/sqlbunny/sqlbunny/sqlschema/schema"
@@ -93,6 +94,16 @@ func (s *Schema) SQLSchema() *schema.Database {
 				ForeignTable:   f.ForeignModel,
 				LocalColumns:   sqlNameAll(f.LocalFields),
 				ForeignColumns: sqlNameAll(f.ForeignFields),
+				OnDelete:       f.OnDelete,
+				OnUpdate:       f.OnUpdate,
+				Deferrable:     f.Deferrable,
+			}
+		}
+
+		for _, c := range m.Checks {
+			t.Checks[makeName(m.Name, []Path{{c.Name}}, "check")] = &schema.Check{
+				Expr:     c.Expr,
+				NotValid: c.NotValid,
 			}
 		}
 	}
@@ -120,12 +131,15 @@ func doCalcFields(m *Model, t *schema.Table, f *Field, forceNullable bool, prefi
 				Type:     "boolean",
 				Default:  def,
 				Nullable: forceNullable,
+				Position: t.NextColumnPosition(),
 			}
 		}
 	case BaseType:
 		nullable := f.Nullable || forceNullable
 		var def string
-		if !nullable {
+		if f.Default != "" {
+			def = f.Default
+		} else if !nullable {
 			def = ty.SQLType().ZeroValue
 		}
 
@@ -134,6 +148,7 @@ func doCalcFields(m *Model, t *schema.Table, f *Field, forceNullable bool, prefi
 			Type:     ty.SQLType().Type,
 			Default:  def,
 			Nullable: nullable,
+			Position: t.NextColumnPosition(),
 		}
 	default:
 		// Should never happen, because all types except Struct
@@ -141,3 +156,62 @@ func doCalcFields(m *Model, t *schema.Table, f *Field, forceNullable bool, prefi
 		panic("unknown type")
 	}
 }
+
+// SQLRenames returns the table and column renames of the models and fields
+// with RenamedFrom set, to be passed to diff.DiffWithOptions.
+func (s *Schema) SQLRenames() ([]schema.TableRename, []schema.ColumnRename) {
+	var tables []schema.TableRename
+	var columns []schema.ColumnRename
+
+	for _, name := range sortedModelNames(s.Models) {
+		m := s.Models[name]
+		if m.RenamedFrom != "" {
+			tables = append(tables, schema.TableRename{
+				OldName: m.RenamedFrom,
+				NewName: m.Name,
+			})
+		}
+		for _, f := range m.Fields {
+			columns = doCalcRenames(columns, m, f, nil, nil)
+		}
+	}
+	return tables, columns
+}
+
+// doCalcRenames appends the column renames of field f. prefix and oldPrefix are
+// the new and old paths of the struct containing f.
+func doCalcRenames(res []schema.ColumnRename, m *Model, f *Field, prefix, oldPrefix Path) []schema.ColumnRename {
+	oldName := f.Name
+	if f.RenamedFrom != "" {
+		oldName = f.RenamedFrom
+	}
+	path := appendPath(prefix, f.Name)
+	oldPath := appendPath(oldPrefix, oldName)
+
+	if ty, ok := f.Type.(*Struct); ok {
+		for _, f2 := range ty.Fields {
+			res = doCalcRenames(res, m, f2, path, oldPath)
+		}
+		if !f.Nullable {
+			return res
+		}
+	}
+
+	if !path.Equals(oldPath) {
+		res = append(res, schema.ColumnRename{
+			TableName: m.Name,
+			OldName:   oldPath.SQLName(),
+			NewName:   path.SQLName(),
+		})
+	}
+	return res
+}
+
+func sortedModelNames(models map[string]*Model) []string {
+	res := make([]string, 0, len(models))
+	for name := range models {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
@@ -0,0 +1,97 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sqlbunny/sqlbunny/sqlschema/diff"
+	sqlschema "github.com/sqlbunny/sqlbunny/sqlschema/schema"
+)
+
+var (
+	textType  = &BaseTypeNotNullable{Name: "string", Postgres: SQLType{Type: "text", ZeroValue: "''"}}
+	int64Type = &BaseTypeNotNullable{Name: "int64", Postgres: SQLType{Type: "bigint", ZeroValue: "0"}}
+)
+
+func renameSchemas() (*Schema, *Schema) {
+	old := New()
+	old.Models["books"] = &Model{
+		Name: "books",
+		Fields: []*Field{
+			{Name: "id", Type: textType},
+			{Name: "name", Type: textType},
+			{Name: "cost", Nullable: true, Type: &Struct{Name: "money", Fields: []*Field{
+				{Name: "value", Type: int64Type},
+				{Name: "currency", Type: textType},
+			}}},
+		},
+		PrimaryKey: &PrimaryKey{Fields: []Path{{"id"}}},
+		Uniques:    []*Unique{{Fields: []Path{{"name"}}}},
+		Indexes:    []*Index{{Fields: []Path{{"cost", "currency"}}}},
+	}
+
+	renamed := New()
+	renamed.Models["book"] = &Model{
+		Name:        "book",
+		RenamedFrom: "books",
+		Fields: []*Field{
+			{Name: "id", Type: textType},
+			{Name: "title", RenamedFrom: "name", Type: textType},
+			{Name: "price", RenamedFrom: "cost", Nullable: true, Type: &Struct{Name: "money", Fields: []*Field{
+				{Name: "amount", RenamedFrom: "value", Type: int64Type},
+				{Name: "currency", Type: textType},
+			}}},
+		},
+		PrimaryKey: &PrimaryKey{Fields: []Path{{"id"}}},
+		Uniques:    []*Unique{{Fields: []Path{{"title"}}}},
+		Indexes:    []*Index{{Fields: []Path{{"price", "currency"}}}},
+	}
+	return old, renamed
+}
+
+func TestSQLRenames(t *testing.T) {
+	_, renamed := renameSchemas()
+
+	tables, columns := renamed.SQLRenames()
+	wantTables := []sqlschema.TableRename{{OldName: "books", NewName: "book"}}
+	if !reflect.DeepEqual(tables, wantTables) {
+		t.Errorf("bad table renames: %#v", tables)
+	}
+	wantColumns := []sqlschema.ColumnRename{
+		{TableName: "book", OldName: "name", NewName: "title"},
+		{TableName: "book", OldName: "cost__value", NewName: "price__amount"},
+		{TableName: "book", OldName: "cost__currency", NewName: "price__currency"},
+		{TableName: "book", OldName: "cost", NewName: "price"},
+	}
+	if !reflect.DeepEqual(columns, wantColumns) {
+		t.Errorf("bad column renames: %#v", columns)
+	}
+}
+
+func TestSQLRenamesDiff(t *testing.T) {
+	old, renamed := renameSchemas()
+
+	tables, columns := renamed.SQLRenames()
+	ops := diff.DiffWithOptions(old.SQLSchema(), renamed.SQLSchema(), diff.Options{
+		TableRenames:  tables,
+		ColumnRenames: columns,
+	})
+
+	var got []string
+	for _, op := range ops {
+		got = append(got, op.GetSQL())
+	}
+	want := []string{
+		`ALTER TABLE "books" RENAME TO "book"`,
+		`ALTER TABLE "book" RENAME CONSTRAINT "books_pkey" TO "book_pkey"`,
+		`ALTER TABLE "book" RENAME COLUMN "name" TO "title"`,
+		`ALTER TABLE "book" RENAME COLUMN "cost__value" TO "price__amount"`,
+		`ALTER TABLE "book" RENAME COLUMN "cost__currency" TO "price__currency"`,
+		`ALTER TABLE "book" RENAME COLUMN "cost" TO "price"`,
+		`ALTER TABLE "book" RENAME CONSTRAINT "books___name___key" TO "book___title___key"`,
+		`ALTER INDEX "books___cost__currency___idx" RENAME TO "book___price__currency___idx"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bad operations:\n%q\nwant:\n%q", got, want)
+	}
+}
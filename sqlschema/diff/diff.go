@@ -7,6 +7,13 @@ import (
 	"github.com/sqlbunny/sqlbunny/sqlschema/schema"
 )
 
+// Diff returns the operations that turn d1 into d2.
+//
+// The result is deterministic. Operations are grouped in phases, so that
+// constraints are dropped before what they depend on, and created after it.
+// Within a phase, tables are ordered so that tables come after the tables
+// they reference, and everything else is ordered by name, except columns
+// which are created in position order.
 func Diff(d1, d2 *schema.Database) []operations.Operation {
 	var ops []operations.Operation
 	ops = diffDropForeignKeys(ops, d1, d2)
@@ -92,67 +99,69 @@ func hasForeignKey(d *schema.Database, schemaName, tableName string, name string
 	return reflect.DeepEqual(k, k2)
 }
 
+// getCheck returns the check with the same name and expression as k, ignoring
+// whether it's valid, or nil if there's none.
+func getCheck(d *schema.Database, schemaName, tableName string, name string, k *schema.Check) *schema.Check {
+	t := getTable(d, schemaName, tableName)
+	if t == nil {
+		return nil
+	}
+	k2, ok := t.Checks[name]
+	if !ok || k2.Expr != k.Expr {
+		return nil
+	}
+	return k2
+}
+
 func diffDropForeignKeys(ops []operations.Operation, d1, d2 *schema.Database) []operations.Operation {
-	for schemaName, s1 := range d1.Schemas {
-		for tableName, t1 := range s1.Tables {
-			var subops []operations.AlterTableSuboperation
-
-			for name, k := range t1.ForeignKeys {
-				if !hasForeignKey(d2, schemaName, tableName, name, k) {
-					subops = append(subops, &operations.AlterTableDropForeignKey{Name: name})
-				}
-			}
+	for _, t := range sortedTables(d1) {
+		var subops []operations.AlterTableSuboperation
 
-			if len(subops) != 0 {
-				ops = append(ops, operations.AlterTable{
-					SchemaName: schemaName,
-					TableName:  tableName,
-					Ops:        subops,
-				})
+		for _, name := range sortedKeys(t.Table.ForeignKeys) {
+			if !hasForeignKey(d2, t.SchemaName, t.TableName, name, t.Table.ForeignKeys[name]) {
+				subops = append(subops, operations.AlterTableDropForeignKey{Name: name})
 			}
 		}
+
+		ops = appendAlterTable(ops, t, subops)
 	}
 	return ops
 }
 
 func diffDropConstraints(ops []operations.Operation, d1, d2 *schema.Database) []operations.Operation {
-	for schemaName, s1 := range d1.Schemas {
-		for tableName, t1 := range s1.Tables {
-			var subops []operations.AlterTableSuboperation
+	for _, t := range sortedTables(d1) {
+		var subops []operations.AlterTableSuboperation
 
-			if t1.PrimaryKey != nil && !hasPrimaryKey(d2, schemaName, tableName, t1.PrimaryKey) {
-				subops = append(subops, operations.AlterTableDropPrimaryKey{})
-			}
+		if t.Table.PrimaryKey != nil && !hasPrimaryKey(d2, t.SchemaName, t.TableName, t.Table.PrimaryKey) {
+			subops = append(subops, operations.AlterTableDropPrimaryKey{})
+		}
 
-			for name, k := range t1.Uniques {
-				if !hasUnique(d2, schemaName, tableName, name, k) {
-					subops = append(subops, operations.AlterTableDropUnique{Name: name})
-				}
+		for _, name := range sortedKeys(t.Table.Uniques) {
+			if !hasUnique(d2, t.SchemaName, t.TableName, name, t.Table.Uniques[name]) {
+				subops = append(subops, operations.AlterTableDropUnique{Name: name})
 			}
+		}
 
-			if len(subops) != 0 {
-				ops = append(ops, operations.AlterTable{
-					SchemaName: schemaName,
-					TableName:  tableName,
-					Ops:        subops,
-				})
+		for _, name := range sortedKeys(t.Table.Checks) {
+			if getCheck(d2, t.SchemaName, t.TableName, name, t.Table.Checks[name]) == nil {
+				subops = append(subops, operations.AlterTableDropCheck{Name: name})
 			}
 		}
+
+		ops = appendAlterTable(ops, t, subops)
 	}
 	return ops
 }
 
 func diffDropIndexes(ops []operations.Operation, d1, d2 *schema.Database) []operations.Operation {
-	for schemaName, s1 := range d1.Schemas {
-		for tableName, t1 := range s1.Tables {
-			for name, k := range t1.Indexes {
-				if !hasIndex(d2, schemaName, tableName, name, k) {
-					ops = append(ops, operations.DropIndex{
-						SchemaName: schemaName,
-						TableName:  tableName,
-						IndexName:  name,
-					})
-				}
+	for _, t := range sortedTables(d1) {
+		for _, name := range sortedKeys(t.Table.Indexes) {
+			if !hasIndex(d2, t.SchemaName, t.TableName, name, t.Table.Indexes[name]) {
+				ops = append(ops, operations.DropIndex{
+					SchemaName: t.SchemaName,
+					TableName:  t.TableName,
+					IndexName:  name,
+				})
 			}
 		}
 	}
@@ -160,21 +169,22 @@ func diffDropIndexes(ops []operations.Operation, d1, d2 *schema.Database) []oper
 }
 
 func diffDropTables(ops []operations.Operation, d1, d2 *schema.Database) []operations.Operation {
-	for schemaName, s1 := range d1.Schemas {
-		for tableName := range s1.Tables {
-			if getTable(d2, schemaName, tableName) == nil {
-				ops = append(ops, operations.DropTable{
-					SchemaName: schemaName,
-					TableName:  tableName,
-				})
-			}
+	// Drop the tables referencing other tables first.
+	tables := sortedTables(d1)
+	for i := len(tables) - 1; i >= 0; i-- {
+		t := tables[i]
+		if getTable(d2, t.SchemaName, t.TableName) == nil {
+			ops = append(ops, operations.DropTable{
+				SchemaName: t.SchemaName,
+				TableName:  t.TableName,
+			})
 		}
 	}
 	return ops
 }
 
 func diffDropSchemas(ops []operations.Operation, d1, d2 *schema.Database) []operations.Operation {
-	for schemaName := range d1.Schemas {
+	for _, schemaName := range sortedKeys(d1.Schemas) {
 		if d2.Schemas[schemaName] == nil {
 			ops = append(ops, operations.DropSchema{
 				SchemaName: schemaName,
@@ -185,45 +195,40 @@ func diffDropSchemas(ops []operations.Operation, d1, d2 *schema.Database) []oper
 }
 
 func diffAlterTables(ops []operations.Operation, d1, d2 *schema.Database) []operations.Operation {
-	for schemaName, s1 := range d1.Schemas {
-		for tableName, t1 := range s1.Tables {
-			if t2 := getTable(d2, schemaName, tableName); t2 != nil {
-				var subops []operations.AlterTableSuboperation
-				for name, c1 := range t1.Columns {
-					c2, ok := t2.Columns[name]
-					if ok {
-						subops = diffColumn(subops, name, c1, c2)
-					} else {
-						subops = append(subops, operations.AlterTableDropColumn{Name: name})
-					}
-				}
-				for name, c2 := range t2.Columns {
-					_, ok := t1.Columns[name]
-					if !ok {
-						subops = append(subops, operations.AlterTableAddColumn{
-							Name:     name,
-							Type:     c2.Type,
-							Default:  c2.Default,
-							Nullable: c2.Nullable,
-						})
-					}
-				}
-
-				if len(subops) != 0 {
-					ops = append(ops, operations.AlterTable{
-						SchemaName: schemaName,
-						TableName:  tableName,
-						Ops:        subops,
-					})
-				}
+	for _, t := range sortedTables(d1) {
+		t1 := t.Table
+		t2 := getTable(d2, t.SchemaName, t.TableName)
+		if t2 == nil {
+			continue
+		}
+
+		var subops []operations.AlterTableSuboperation
+		for _, name := range sortedKeys(t1.Columns) {
+			if _, ok := t2.Columns[name]; !ok {
+				subops = append(subops, operations.AlterTableDropColumn{Name: name})
 			}
 		}
+		for _, name := range t2.ColumnNames() {
+			c2 := t2.Columns[name]
+			if c1, ok := t1.Columns[name]; ok {
+				subops = diffColumn(subops, name, c1, c2)
+			} else {
+				subops = append(subops, operations.AlterTableAddColumn{
+					Name:     name,
+					Type:     c2.Type,
+					Default:  c2.Default,
+					Nullable: c2.Nullable,
+				})
+			}
+		}
+
+		ops = appendAlterTable(ops, t, subops)
 	}
 	return ops
 }
 
 func diffCreateSchemas(ops []operations.Operation, d1, d2 *schema.Database) []operations.Operation {
-	for schemaName := range d2.Schemas {
+	for _, schemaName := range sortedKeys(d2.Schemas) {
 		if d1.Schemas[schemaName] == nil {
 			ops = append(ops, operations.CreateSchema{
 				SchemaName: schemaName,
@@ -234,44 +239,44 @@ func diffCreateSchemas(ops []operations.Operation, d1, d2 *schema.Database) []op
 }
 
 func diffCreateTables(ops []operations.Operation, d1, d2 *schema.Database) []operations.Operation {
-	for schemaName, s2 := range d2.Schemas {
-		for tableName, t2 := range s2.Tables {
-			if t1 := getTable(d1, schemaName, tableName); t1 == nil {
-				var cols []operations.Column
-				for name, c := range t2.Columns {
-					cols = append(cols, operations.Column{
-						Name:     name,
-						Type:     c.Type,
-						Default:  c.Default,
-						Nullable: c.Nullable,
-					})
-				}
-
-				ops = append(ops, operations.CreateTable{
-					SchemaName: schemaName,
-					TableName:  tableName,
-					Columns:    cols,
-				})
-			}
+	for _, t := range sortedTables(d2) {
+		if getTable(d1, t.SchemaName, t.TableName) != nil {
+			continue
 		}
+
+		var cols []operations.Column
+		for _, name := range t.Table.ColumnNames() {
+			c := t.Table.Columns[name]
+			cols = append(cols, operations.Column{
+				Name:     name,
+				Type:     c.Type,
+				Default:  c.Default,
+				Nullable: c.Nullable,
+			})
+		}
+
+		ops = append(ops, operations.CreateTable{
+			SchemaName: t.SchemaName,
+			TableName:  t.TableName,
+			Columns:    cols,
+		})
 	}
 	return ops
 }
 
 func diffCreateIndexes(ops []operations.Operation, d1, d2 *schema.Database) []operations.Operation {
-	for schemaName, s2 := range d2.Schemas {
-		for tableName, t2 := range s2.Tables {
-			for name, i2 := range t2.Indexes {
-				if !hasIndex(d1, schemaName, tableName, name, i2) {
-					ops = append(ops, operations.CreateIndex{
-						SchemaName: schemaName,
-						TableName:  tableName,
-						IndexName:  name,
-						Columns:    i2.Columns,
-						Method:     i2.Method,
-						Where:      i2.Where,
-					})
-				}
+	for _, t := range sortedTables(d2) {
+		for _, name := range sortedKeys(t.Table.Indexes) {
+			i2 := t.Table.Indexes[name]
+			if !hasIndex(d1, t.SchemaName, t.TableName, name, i2) {
+				ops = append(ops, operations.CreateIndex{
+					SchemaName: t.SchemaName,
+					TableName:  t.TableName,
+					IndexName:  name,
+					Columns:    i2.Columns,
+					Method:     i2.Method,
+					Where:      i2.Where,
+				})
 			}
 		}
 	}
@@ -279,60 +284,66 @@ func diffCreateIndexes(ops []operations.Operation, d1, d2 *schema.Database) []op
 }
 
 func diffCreateConstraints(ops []operations.Operation, d1, d2 *schema.Database) []operations.Operation {
-	for schemaName, s2 := range d2.Schemas {
-		for tableName, t2 := range s2.Tables {
-			var subops []operations.AlterTableSuboperation
+	for _, t := range sortedTables(d2) {
+		t2 := t.Table
+		var subops []operations.AlterTableSuboperation
 
-			if t2.PrimaryKey != nil && !hasPrimaryKey(d1, schemaName, tableName, t2.PrimaryKey) {
-				subops = append(subops, operations.AlterTableCreatePrimaryKey{Columns: t2.PrimaryKey.Columns})
-			}
+		if t2.PrimaryKey != nil && !hasPrimaryKey(d1, t.SchemaName, t.TableName, t2.PrimaryKey) {
+			subops = append(subops, operations.AlterTableCreatePrimaryKey{Columns: t2.PrimaryKey.Columns})
+		}
 
-			for name, i2 := range t2.Uniques {
-				if !hasUnique(d1, schemaName, tableName, name, i2) {
-					subops = append(subops, operations.AlterTableCreateUnique{
-						Name:    name,
-						Columns: i2.Columns,
-					})
-				}
+		for _, name := range sortedKeys(t2.Uniques) {
+			i2 := t2.Uniques[name]
+			if !hasUnique(d1, t.SchemaName, t.TableName, name, i2) {
+				subops = append(subops, operations.AlterTableCreateUnique{
+					Name:    name,
+					Columns: i2.Columns,
+				})
 			}
+		}
 
-			if len(subops) != 0 {
-				ops = append(ops, operations.AlterTable{
-					SchemaName: schemaName,
-					TableName:  tableName,
-					Ops:        subops,
+		for _, name := range sortedKeys(t2.Checks) {
+			c2 := t2.Checks[name]
+			c1 := getCheck(d1, t.SchemaName, t.TableName, name, c2)
+			if c1 == nil {
+				subops = append(subops, operations.AlterTableCreateCheck{
+					Name:     name,
+					Expr:     c2.Expr,
+					NotValid: c2.NotValid,
 				})
+			} else if c1.NotValid && !c2.NotValid {
+				// The check was added NOT VALID by an earlier migration, and
+				// the model no longer asks for it to stay so.
+				subops = append(subops, operations.AlterTableValidateConstraint{Name: name})
 			}
 		}
+
+		ops = appendAlterTable(ops, t, subops)
 	}
 	return ops
 }
 
 func diffCreateForeignKeys(ops []operations.Operation, d1, d2 *schema.Database) []operations.Operation {
-	for schemaName, s2 := range d2.Schemas {
-		for tableName, t2 := range s2.Tables {
-			var subops []operations.AlterTableSuboperation
-
-			for name, i2 := range t2.ForeignKeys {
-				if !hasForeignKey(d1, schemaName, tableName, name, i2) {
-					subops = append(subops, operations.AlterTableCreateForeignKey{
-						Name:           name,
-						Columns:        i2.LocalColumns,
-						ForeignSchema:  i2.ForeignSchema,
-						ForeignTable:   i2.ForeignTable,
-						ForeignColumns: i2.ForeignColumns,
-					})
-				}
-			}
-
-			if len(subops) != 0 {
-				ops = append(ops, operations.AlterTable{
-					SchemaName: schemaName,
-					TableName:  tableName,
-					Ops:        subops,
+	for _, t := range sortedTables(d2) {
+		var subops []operations.AlterTableSuboperation
+
+		for _, name := range sortedKeys(t.Table.ForeignKeys) {
+			i2 := t.Table.ForeignKeys[name]
+			if !hasForeignKey(d1, t.SchemaName, t.TableName, name, i2) {
+				subops = append(subops, operations.AlterTableCreateForeignKey{
+					Name:           name,
+					Columns:        i2.LocalColumns,
+					ForeignSchema:  i2.ForeignSchema,
+					ForeignTable:   i2.ForeignTable,
+					ForeignColumns: i2.ForeignColumns,
+					OnDelete:       i2.OnDelete,
+					OnUpdate:       i2.OnUpdate,
+					Deferrable:     i2.Deferrable,
 				})
 			}
 		}
+
+		ops = appendAlterTable(ops, t, subops)
 	}
 	return ops
 }
@@ -356,7 +367,7 @@ func diffColumn(ops []operations.AlterTableSuboperation, name string, c1, c2 *sc
 			})
 		}
 	}
-	if c1.Type != c2.Type {
+	if schema.NormalizeType(c1.Type) != schema.NormalizeType(c2.Type) {
 		ops = append(ops, operations.AlterTableSetType{
 			Name: name,
 			Type: c2.Type,
@@ -0,0 +1,120 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sqlbunny/sqlbunny/sqlschema/schema"
+)
+
+// fkDatabase returns a database where "review" references "book", which
+// references "author". "tag" references nothing.
+func fkDatabase() *schema.Database {
+	d := schema.NewDatabase()
+	s := schema.NewSchema()
+	d.Schemas[""] = s
+	for _, name := range []string{"author", "book", "review", "tag"} {
+		t := schema.NewTable()
+		t.Columns["id"] = &schema.Column{Type: "text", Position: 0}
+		t.PrimaryKey = &schema.PrimaryKey{Columns: []string{"id"}}
+		s.Tables[name] = t
+	}
+	s.Tables["book"].Columns["author_id"] = &schema.Column{Type: "text", Position: 1}
+	s.Tables["book"].ForeignKeys["book___author_id___fkey"] = &schema.ForeignKey{
+		LocalColumns:   []string{"author_id"},
+		ForeignTable:   "author",
+		ForeignColumns: []string{"id"},
+	}
+	s.Tables["review"].Columns["book_id"] = &schema.Column{Type: "text", Position: 1}
+	s.Tables["review"].ForeignKeys["review___book_id___fkey"] = &schema.ForeignKey{
+		LocalColumns:   []string{"book_id"},
+		ForeignTable:   "book",
+		ForeignColumns: []string{"id"},
+	}
+	return d
+}
+
+// emptyDatabase returns a database with only the default schema.
+func emptyDatabase() *schema.Database {
+	d := schema.NewDatabase()
+	d.Schemas[""] = schema.NewSchema()
+	return d
+}
+
+func TestDiffCreateOrder(t *testing.T) {
+	got := sqls(Diff(emptyDatabase(), fkDatabase()))
+	want := []string{
+		"CREATE TABLE \"author\" (\n    \"id\" text NOT NULL\n)",
+		"CREATE TABLE \"book\" (\n    \"id\" text NOT NULL,\n    \"author_id\" text NOT NULL\n)",
+		"CREATE TABLE \"review\" (\n    \"id\" text NOT NULL,\n    \"book_id\" text NOT NULL\n)",
+		"CREATE TABLE \"tag\" (\n    \"id\" text NOT NULL\n)",
+		"ALTER TABLE \"author\"\n    ADD CONSTRAINT \"author_pkey\" PRIMARY KEY (\"id\")",
+		"ALTER TABLE \"book\"\n    ADD CONSTRAINT \"book_pkey\" PRIMARY KEY (\"id\")",
+		"ALTER TABLE \"review\"\n    ADD CONSTRAINT \"review_pkey\" PRIMARY KEY (\"id\")",
+		"ALTER TABLE \"tag\"\n    ADD CONSTRAINT \"tag_pkey\" PRIMARY KEY (\"id\")",
+		"ALTER TABLE \"book\"\n    ADD CONSTRAINT \"book___author_id___fkey\" FOREIGN KEY (\"author_id\") REFERENCES \"author\" (\"id\")",
+		"ALTER TABLE \"review\"\n    ADD CONSTRAINT \"review___book_id___fkey\" FOREIGN KEY (\"book_id\") REFERENCES \"book\" (\"id\")",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bad operations:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestDiffDropOrder(t *testing.T) {
+	got := sqls(Diff(fkDatabase(), emptyDatabase()))
+	want := []string{
+		"ALTER TABLE \"book\"\n    DROP CONSTRAINT \"book___author_id___fkey\"",
+		"ALTER TABLE \"review\"\n    DROP CONSTRAINT \"review___book_id___fkey\"",
+		"ALTER TABLE \"author\"\n    DROP CONSTRAINT \"author_pkey\"",
+		"ALTER TABLE \"book\"\n    DROP CONSTRAINT \"book_pkey\"",
+		"ALTER TABLE \"review\"\n    DROP CONSTRAINT \"review_pkey\"",
+		"ALTER TABLE \"tag\"\n    DROP CONSTRAINT \"tag_pkey\"",
+		`DROP TABLE "tag"`,
+		`DROP TABLE "review"`,
+		`DROP TABLE "book"`,
+		`DROP TABLE "author"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bad operations:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestDiffStable(t *testing.T) {
+	d2 := fkDatabase()
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		d2.Schemas[""].Tables["book"].Indexes["book___"+name+"___idx"] = &schema.Index{Columns: []string{"id"}}
+		d2.Schemas[""].Tables["book"].Checks["book___"+name+"___check"] = &schema.Check{Expr: "true"}
+	}
+	first := sqls(Diff(emptyDatabase(), d2))
+	for i := 0; i < 50; i++ {
+		// Map iteration order is random, so every run iterates differently.
+		if got := sqls(Diff(emptyDatabase(), d2)); !reflect.DeepEqual(got, first) {
+			t.Fatalf("run %d differs:\n%q\nfirst:\n%q", i, got, first)
+		}
+	}
+}
+
+func TestDiffColumnPositions(t *testing.T) {
+	d1 := database("id")
+	d2 := database("id")
+	table := d2.Schemas[""].Tables["book"]
+	table.Columns["zeta"] = &schema.Column{Type: "text", Position: 1}
+	table.Columns["alpha"] = &schema.Column{Type: "text", Position: 3}
+	table.Columns["mu"] = &schema.Column{Type: "text", Position: 2}
+
+	got := sqls(Diff(d1, d2))
+	want := []string{
+		"ALTER TABLE \"book\"\n    ADD COLUMN \"zeta\" text NOT NULL,\n    ADD COLUMN \"mu\" text NOT NULL,\n    ADD COLUMN \"alpha\" text NOT NULL",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bad operations:\n%q\nwant:\n%q", got, want)
+	}
+
+	got = sqls(Diff(emptyDatabase(), d2))
+	want = []string{
+		"CREATE TABLE \"book\" (\n    \"id\" text NOT NULL,\n    \"zeta\" text NOT NULL,\n    \"mu\" text NOT NULL,\n    \"alpha\" text NOT NULL\n)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bad operations:\n%q\nwant:\n%q", got, want)
+	}
+}
@@ -0,0 +1,67 @@
+package diff
+
+import (
+	"sort"
+
+	"github.com/sqlbunny/sqlbunny/sqlschema/operations"
+	"github.com/sqlbunny/sqlbunny/sqlschema/schema"
+)
+
+type tableRef struct {
+	SchemaName string
+	TableName  string
+	Table      *schema.Table
+}
+
+// sortedTables returns the tables of d, ordered so that every table comes after
+// the tables its foreign keys reference. Ties, and cycles of foreign keys, are
+// broken by schema and table name.
+func sortedTables(d *schema.Database) []tableRef {
+	var res []tableRef
+	visited := make(map[tableRef]bool)
+
+	var visit func(t tableRef)
+	visit = func(t tableRef) {
+		key := tableRef{SchemaName: t.SchemaName, TableName: t.TableName}
+		if visited[key] {
+			return
+		}
+		visited[key] = true
+
+		for _, name := range sortedKeys(t.Table.ForeignKeys) {
+			fk := t.Table.ForeignKeys[name]
+			if ft := getTable(d, fk.ForeignSchema, fk.ForeignTable); ft != nil {
+				visit(tableRef{SchemaName: fk.ForeignSchema, TableName: fk.ForeignTable, Table: ft})
+			}
+		}
+		res = append(res, t)
+	}
+
+	for _, schemaName := range sortedKeys(d.Schemas) {
+		s := d.Schemas[schemaName]
+		for _, tableName := range sortedKeys(s.Tables) {
+			visit(tableRef{SchemaName: schemaName, TableName: tableName, Table: s.Tables[tableName]})
+		}
+	}
+	return res
+}
+
+func appendAlterTable(ops []operations.Operation, t tableRef, subops []operations.AlterTableSuboperation) []operations.Operation {
+	if len(subops) == 0 {
+		return ops
+	}
+	return append(ops, operations.AlterTable{
+		SchemaName: t.SchemaName,
+		TableName:  t.TableName,
+		Ops:        subops,
+	})
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	res := make([]string, 0, len(m))
+	for k := range m {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
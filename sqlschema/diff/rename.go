@@ -0,0 +1,221 @@
+package diff
+
+import (
+	"reflect"
+
+	"github.com/sqlbunny/sqlbunny/sqlschema/operations"
+	"github.com/sqlbunny/sqlbunny/sqlschema/schema"
+)
+
+// Options configures DiffWithOptions.
+type Options struct {
+	// Renames of tables and columns. A rename is only used if the old name
+	// exists in the old database, and the new name only exists in the new one,
+	// so renames that have already been migrated are ignored.
+	TableRenames  []schema.TableRename
+	ColumnRenames []schema.ColumnRename
+
+	// ConfirmRename enables detecting renamed columns. When a column is dropped
+	// and another one with the same type and nullability is added to the same
+	// table, ConfirmRename is called to ask whether it's a rename. If nil, no
+	// renames are detected.
+	ConfirmRename func(r schema.ColumnRename) bool
+}
+
+// DiffWithOptions is like Diff, but renames tables and columns instead of
+// dropping and recreating them, as configured in opts.
+//
+// The names of constraints and indexes include the names of their table and
+// columns, so after renaming those, the constraints and indexes whose name
+// changed but not their definition are renamed too, instead of being dropped
+// and recreated.
+//
+// The rename operations come first, followed by the operations Diff returns
+// for the renamed database.
+func DiffWithOptions(d1, d2 *schema.Database, opts Options) []operations.Operation {
+	d1 = d1.Clone()
+
+	var ops []operations.Operation
+	apply := func(op operations.Operation) {
+		if err := op.Apply(d1); err != nil {
+			panic(err) // Should never happen, renames are checked against d1
+		}
+		ops = append(ops, op)
+	}
+
+	for _, r := range opts.TableRenames {
+		if getTable(d1, r.SchemaName, r.OldName) == nil ||
+			getTable(d1, r.SchemaName, r.NewName) != nil ||
+			getTable(d2, r.SchemaName, r.NewName) == nil {
+			continue
+		}
+		apply(operations.RenameTable{
+			SchemaName:   r.SchemaName,
+			TableName:    r.OldName,
+			NewTableName: r.NewName,
+		})
+		// Diff drops the primary key by the name Postgres gives it, after its table.
+		if getTable(d1, r.SchemaName, r.NewName).PrimaryKey != nil {
+			apply(operations.RenameConstraint{
+				SchemaName:        r.SchemaName,
+				TableName:         r.NewName,
+				Kind:              operations.PrimaryKeyConstraint,
+				OldConstraintName: r.OldName + "_pkey",
+				NewConstraintName: r.NewName + "_pkey",
+			})
+		}
+	}
+
+	for _, r := range opts.ColumnRenames {
+		if !canRenameColumn(d1, d2, r) {
+			continue
+		}
+		apply(operations.RenameColumn{
+			SchemaName:    r.SchemaName,
+			TableName:     r.TableName,
+			OldColumnName: r.OldName,
+			NewColumnName: r.NewName,
+		})
+	}
+
+	if opts.ConfirmRename != nil {
+		for _, r := range detectRenames(d1, d2) {
+			if !canRenameColumn(d1, d2, r) || !opts.ConfirmRename(r) {
+				continue
+			}
+			apply(operations.RenameColumn{
+				SchemaName:    r.SchemaName,
+				TableName:     r.TableName,
+				OldColumnName: r.OldName,
+				NewColumnName: r.NewName,
+			})
+		}
+	}
+
+	if len(ops) != 0 {
+		for _, op := range renameConstraints(d1, d2) {
+			apply(op)
+		}
+	}
+
+	return append(ops, Diff(d1, d2)...)
+}
+
+// renameConstraints returns the renames of the constraints and indexes of d1
+// missing in d2, to the ones of d2 with the same definition missing in d1.
+func renameConstraints(d1, d2 *schema.Database) []operations.Operation {
+	var ops []operations.Operation
+	for _, t := range sortedTables(d1) {
+		t1 := t.Table
+		t2 := getTable(d2, t.SchemaName, t.TableName)
+		if t2 == nil {
+			continue
+		}
+
+		renameConstraint := func(kind operations.ConstraintKind) func(oldName, newName string) {
+			return func(oldName, newName string) {
+				ops = append(ops, operations.RenameConstraint{
+					SchemaName:        t.SchemaName,
+					TableName:         t.TableName,
+					Kind:              kind,
+					OldConstraintName: oldName,
+					NewConstraintName: newName,
+				})
+			}
+		}
+		matchRenames(t1.Uniques, t2.Uniques, func(u1, u2 *schema.Unique) bool {
+			return reflect.DeepEqual(u1, u2)
+		}, renameConstraint(operations.UniqueConstraint))
+		matchRenames(t1.ForeignKeys, t2.ForeignKeys, func(fk1, fk2 *schema.ForeignKey) bool {
+			return reflect.DeepEqual(fk1, fk2)
+		}, renameConstraint(operations.ForeignKeyConstraint))
+		matchRenames(t1.Checks, t2.Checks, func(c1, c2 *schema.Check) bool {
+			return c1.Expr == c2.Expr
+		}, renameConstraint(operations.CheckConstraint))
+
+		matchRenames(t1.Indexes, t2.Indexes, func(i1, i2 *schema.Index) bool {
+			return reflect.DeepEqual(i1, i2)
+		}, func(oldName, newName string) {
+			ops = append(ops, operations.RenameIndex{
+				SchemaName:   t.SchemaName,
+				TableName:    t.TableName,
+				OldIndexName: oldName,
+				NewIndexName: newName,
+			})
+		})
+	}
+	return ops
+}
+
+// matchRenames calls rename for every item of m1 missing in m2 that is equal
+// to an item of m2 missing in m1. Every item is renamed at most once.
+func matchRenames[T any](m1, m2 map[string]T, equal func(a, b T) bool, rename func(oldName, newName string)) {
+	used := make(map[string]bool)
+	for _, oldName := range sortedKeys(m1) {
+		if _, ok := m2[oldName]; ok {
+			continue
+		}
+		for _, newName := range sortedKeys(m2) {
+			if _, ok := m1[newName]; ok || used[newName] {
+				continue
+			}
+			if equal(m1[oldName], m2[newName]) {
+				used[newName] = true
+				rename(oldName, newName)
+				break
+			}
+		}
+	}
+}
+
+func canRenameColumn(d1, d2 *schema.Database, r schema.ColumnRename) bool {
+	t1 := getTable(d1, r.SchemaName, r.TableName)
+	t2 := getTable(d2, r.SchemaName, r.TableName)
+	if t1 == nil || t2 == nil {
+		return false
+	}
+	_, oldExists := t1.Columns[r.OldName]
+	_, newExists := t1.Columns[r.NewName]
+	_, newWanted := t2.Columns[r.NewName]
+	_, oldWanted := t2.Columns[r.OldName]
+	return oldExists && !newExists && newWanted && !oldWanted
+}
+
+// detectRenames returns the possible column renames: pairs of a dropped and an
+// added column of the same table with the same type and nullability. A column
+// can be in several candidates, DiffWithOptions skips the candidates whose
+// columns have already been renamed.
+func detectRenames(d1, d2 *schema.Database) []schema.ColumnRename {
+	var res []schema.ColumnRename
+	for _, schemaName := range sortedKeys(d1.Schemas) {
+		s1 := d1.Schemas[schemaName]
+		for _, tableName := range sortedKeys(s1.Tables) {
+			t1 := s1.Tables[tableName]
+			t2 := getTable(d2, schemaName, tableName)
+			if t2 == nil {
+				continue
+			}
+			for _, oldName := range sortedKeys(t1.Columns) {
+				if _, ok := t2.Columns[oldName]; ok {
+					continue
+				}
+				c1 := t1.Columns[oldName]
+				for _, newName := range sortedKeys(t2.Columns) {
+					if _, ok := t1.Columns[newName]; ok {
+						continue
+					}
+					c2 := t2.Columns[newName]
+					if c1.Nullable == c2.Nullable && schema.NormalizeType(c1.Type) == schema.NormalizeType(c2.Type) {
+						res = append(res, schema.ColumnRename{
+							SchemaName: schemaName,
+							TableName:  tableName,
+							OldName:    oldName,
+							NewName:    newName,
+						})
+					}
+				}
+			}
+		}
+	}
+	return res
+}
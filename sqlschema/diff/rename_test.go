@@ -0,0 +1,104 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sqlbunny/sqlbunny/sqlschema/operations"
+	"github.com/sqlbunny/sqlbunny/sqlschema/schema"
+)
+
+// database returns a database with a table "book" with the given columns, of
+// type text, nullable if their name starts with "n_".
+func database(columns ...string) *schema.Database {
+	d := schema.NewDatabase()
+	s := schema.NewSchema()
+	d.Schemas[""] = s
+	t := schema.NewTable()
+	s.Tables["book"] = t
+	for i, name := range columns {
+		t.Columns[name] = &schema.Column{
+			Type:     "text",
+			Nullable: len(name) > 2 && name[:2] == "n_",
+			Position: i,
+		}
+	}
+	return d
+}
+
+func sqls(ops []operations.Operation) []string {
+	var res []string
+	for _, op := range ops {
+		res = append(res, op.GetSQL())
+	}
+	return res
+}
+
+func TestDiffWithOptionsColumnRenames(t *testing.T) {
+	d1 := database("id", "name")
+	d2 := database("id", "title")
+	ops := DiffWithOptions(d1, d2, Options{
+		ColumnRenames: []schema.ColumnRename{
+			{TableName: "book", OldName: "name", NewName: "title"},
+			// Already migrated, ignored.
+			{TableName: "book", OldName: "old", NewName: "id"},
+		},
+	})
+
+	want := []string{`ALTER TABLE "book" RENAME COLUMN "name" TO "title"`}
+	if got := sqls(ops); !reflect.DeepEqual(got, want) {
+		t.Errorf("bad operations: %q", got)
+	}
+}
+
+func TestDiffWithOptionsDetectRenames(t *testing.T) {
+	d1 := database("id", "name", "n_note")
+	d2 := database("id", "title", "n_comment")
+
+	var asked []schema.ColumnRename
+	ops := DiffWithOptions(d1, d2, Options{
+		ConfirmRename: func(r schema.ColumnRename) bool {
+			asked = append(asked, r)
+			return r.OldName == "name"
+		},
+	})
+
+	// Columns are only renamed to columns of the same type and nullability.
+	wantAsked := []schema.ColumnRename{
+		{TableName: "book", OldName: "n_note", NewName: "n_comment"},
+		{TableName: "book", OldName: "name", NewName: "title"},
+	}
+	if !reflect.DeepEqual(asked, wantAsked) {
+		t.Errorf("bad candidates: %#v", asked)
+	}
+
+	// The rejected rename is a drop and an add.
+	want := []string{
+		`ALTER TABLE "book" RENAME COLUMN "name" TO "title"`,
+		"ALTER TABLE \"book\"\n    DROP COLUMN \"n_note\",\n    ADD COLUMN \"n_comment\" text",
+	}
+	if got := sqls(ops); !reflect.DeepEqual(got, want) {
+		t.Errorf("bad operations: %q", got)
+	}
+}
+
+func TestDiffWithOptionsTableRename(t *testing.T) {
+	d1 := database("id")
+	d1.Schemas[""].Tables["books"] = d1.Schemas[""].Tables["book"]
+	delete(d1.Schemas[""].Tables, "book")
+	d1.Schemas[""].Tables["books"].Checks["books___positive___check"] = &schema.Check{Expr: "id <> ''"}
+	d2 := database("id")
+	d2.Schemas[""].Tables["book"].Checks["book___positive___check"] = &schema.Check{Expr: "id <> ''"}
+
+	ops := DiffWithOptions(d1, d2, Options{
+		TableRenames: []schema.TableRename{{OldName: "books", NewName: "book"}},
+	})
+
+	want := []string{
+		`ALTER TABLE "books" RENAME TO "book"`,
+		`ALTER TABLE "book" RENAME CONSTRAINT "books___positive___check" TO "book___positive___check"`,
+	}
+	if got := sqls(ops); !reflect.DeepEqual(got, want) {
+		t.Errorf("bad operations: %q", got)
+	}
+}
@@ -0,0 +1,392 @@
+// Package introspect reads the schema of a live Postgres database from the
+// system catalogs, so it can be compared with the models or the migrations
+// using diff.Diff.
+package introspect
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+	"github.com/sqlbunny/sqlbunny/sqlschema/schema"
+)
+
+// Options configures Introspect.
+type Options struct {
+	// Schemas to read. The default schema ("public") is named "".
+	// If empty, all schemas except the system ones are read.
+	Schemas []string
+
+	// ExcludeTables are not read. Tables are named "table" for the default
+	// schema, and "schema.table" for the others.
+	ExcludeTables []string
+}
+
+const (
+	selectSchemasSQL = `SELECT n.nspname
+FROM pg_catalog.pg_namespace n
+WHERE n.nspname NOT IN ('pg_catalog', 'information_schema') AND n.nspname NOT LIKE 'pg\_%'`
+
+	selectTablesSQL = `SELECT c.oid, n.nspname, c.relname
+FROM pg_catalog.pg_class c
+JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+WHERE c.relkind IN ('r', 'p') AND NOT c.relispartition
+AND n.nspname NOT IN ('pg_catalog', 'information_schema') AND n.nspname NOT LIKE 'pg\_%'`
+
+	selectColumnsSQL = `SELECT a.attrelid, a.attname, format_type(a.atttypid, a.atttypmod), NOT a.attnotnull, pg_get_expr(d.adbin, d.adrelid)
+FROM pg_catalog.pg_attribute a
+JOIN pg_catalog.pg_class c ON c.oid = a.attrelid
+LEFT JOIN pg_catalog.pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+WHERE c.relkind IN ('r', 'p') AND a.attnum > 0 AND NOT a.attisdropped
+ORDER BY a.attrelid, a.attnum`
+
+	selectConstraintsSQL = `SELECT c.conrelid, c.conname, c.contype, a.attname, fn.nspname, fc.relname, fa.attname,
+	c.confdeltype, c.confupdtype, c.condeferrable AND c.condeferred
+FROM pg_catalog.pg_constraint c
+CROSS JOIN LATERAL unnest(c.conkey, c.confkey) WITH ORDINALITY AS k(attnum, fattnum, ord)
+JOIN pg_catalog.pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+LEFT JOIN pg_catalog.pg_attribute fa ON fa.attrelid = c.confrelid AND fa.attnum = k.fattnum
+LEFT JOIN pg_catalog.pg_class fc ON fc.oid = c.confrelid
+LEFT JOIN pg_catalog.pg_namespace fn ON fn.oid = fc.relnamespace
+WHERE c.contype IN ('p', 'u', 'f')
+ORDER BY c.conrelid, c.conname, k.ord`
+
+	selectChecksSQL = `SELECT c.conrelid, c.conname, pg_get_expr(c.conbin, c.conrelid), NOT c.convalidated
+FROM pg_catalog.pg_constraint c
+WHERE c.contype = 'c'
+ORDER BY c.conrelid, c.conname`
+
+	selectIndexesSQL = `SELECT i.indrelid, ic.relname, am.amname, pg_get_indexdef(i.indexrelid, k.ord, true), pg_get_expr(i.indpred, i.indrelid, true)
+FROM pg_catalog.pg_index i
+JOIN pg_catalog.pg_class ic ON ic.oid = i.indexrelid
+JOIN pg_catalog.pg_am am ON am.oid = ic.relam
+CROSS JOIN LATERAL generate_series(1, i.indnkeyatts) AS k(ord)
+WHERE NOT EXISTS (
+	SELECT 1 FROM pg_catalog.pg_constraint c
+	WHERE c.conindid = i.indexrelid AND c.contype IN ('p', 'u', 'x')
+)
+ORDER BY i.indrelid, ic.relname, k.ord`
+)
+
+type introspector struct {
+	opts   Options
+	d      *schema.Database
+	tables map[int64]*schema.Table
+}
+
+// Introspect reads the schema of the database in ctx.
+//
+// Column types are returned as format_type names, for example
+// "timestamp with time zone" instead of "timestamptz". Compare them with
+// schema.NormalizeType. Redundant casts are removed from column defaults.
+// Index where clauses and check expressions are returned as deparsed by Postgres, so they may not
+// match the text they were created with. Unique indexes that don't back a
+// unique constraint are returned as plain indexes. Foreign keys that are
+// DEFERRABLE INITIALLY IMMEDIATE are returned as not deferrable.
+func Introspect(ctx context.Context, opts Options) (*schema.Database, error) {
+	i := &introspector{
+		opts:   opts,
+		d:      schema.NewDatabase(),
+		tables: make(map[int64]*schema.Table),
+	}
+
+	steps := []struct {
+		name string
+		fn   func(ctx context.Context) error
+	}{
+		{"schemas", i.readSchemas},
+		{"tables", i.readTables},
+		{"columns", i.readColumns},
+		{"constraints", i.readConstraints},
+		{"checks", i.readChecks},
+		{"indexes", i.readIndexes},
+	}
+	for _, s := range steps {
+		if err := s.fn(ctx); err != nil {
+			return nil, fmt.Errorf("introspect: reading %s: %w", s.name, err)
+		}
+	}
+	return i.d, nil
+}
+
+// schemaName converts a Postgres schema name to the sqlbunny name.
+func schemaName(nspname string) string {
+	if nspname == "public" {
+		return ""
+	}
+	return nspname
+}
+
+func (i *introspector) includeSchema(name string) bool {
+	if len(i.opts.Schemas) == 0 {
+		return true
+	}
+	for _, s := range i.opts.Schemas {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (i *introspector) includeTable(schemaName, tableName string) bool {
+	if !i.includeSchema(schemaName) {
+		return false
+	}
+	name := tableName
+	if schemaName != "" {
+		name = schemaName + "." + tableName
+	}
+	for _, t := range i.opts.ExcludeTables {
+		if t == name {
+			return false
+		}
+	}
+	return true
+}
+
+func (i *introspector) readSchemas(ctx context.Context) error {
+	rows, err := bunny.Query(ctx, selectSchemasSQL)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var nspname string
+		if err := rows.Scan(&nspname); err != nil {
+			return err
+		}
+		name := schemaName(nspname)
+		if i.includeSchema(name) {
+			i.d.Schemas[name] = schema.NewSchema()
+		}
+	}
+	return rows.Err()
+}
+
+func (i *introspector) readTables(ctx context.Context) error {
+	rows, err := bunny.Query(ctx, selectTablesSQL)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var oid int64
+		var nspname, relname string
+		if err := rows.Scan(&oid, &nspname, &relname); err != nil {
+			return err
+		}
+		name := schemaName(nspname)
+		if !i.includeTable(name, relname) {
+			continue
+		}
+		s, ok := i.d.Schemas[name]
+		if !ok {
+			s = schema.NewSchema()
+			i.d.Schemas[name] = s
+		}
+		t := schema.NewTable()
+		s.Tables[relname] = t
+		i.tables[oid] = t
+	}
+	return rows.Err()
+}
+
+func (i *introspector) readColumns(ctx context.Context) error {
+	rows, err := bunny.Query(ctx, selectColumnsSQL)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var oid int64
+		var name, typ string
+		var nullable bool
+		var def sql.NullString
+		if err := rows.Scan(&oid, &name, &typ, &nullable, &def); err != nil {
+			return err
+		}
+		t, ok := i.tables[oid]
+		if !ok {
+			continue
+		}
+		t.Columns[name] = &schema.Column{
+			Type:     typ,
+			Default:  normalizeDefault(def.String),
+			Nullable: nullable,
+			Position: t.NextColumnPosition(),
+		}
+	}
+	return rows.Err()
+}
+
+func (i *introspector) readConstraints(ctx context.Context) error {
+	rows, err := bunny.Query(ctx, selectConstraintsSQL)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var oid int64
+		var name, typ, column string
+		var foreignSchema, foreignTable, foreignColumn sql.NullString
+		var onDelete, onUpdate string
+		var deferred bool
+		if err := rows.Scan(&oid, &name, &typ, &column, &foreignSchema, &foreignTable, &foreignColumn, &onDelete, &onUpdate, &deferred); err != nil {
+			return err
+		}
+		t, ok := i.tables[oid]
+		if !ok {
+			continue
+		}
+		switch typ {
+		case "p":
+			if t.PrimaryKey == nil {
+				t.PrimaryKey = &schema.PrimaryKey{}
+			}
+			t.PrimaryKey.Columns = append(t.PrimaryKey.Columns, column)
+		case "u":
+			u, ok := t.Uniques[name]
+			if !ok {
+				u = &schema.Unique{}
+				t.Uniques[name] = u
+			}
+			u.Columns = append(u.Columns, column)
+		case "f":
+			fk, ok := t.ForeignKeys[name]
+			if !ok {
+				fk = &schema.ForeignKey{
+					ForeignSchema: schemaName(foreignSchema.String),
+					ForeignTable:  foreignTable.String,
+					OnDelete:      foreignKeyActions[onDelete],
+					OnUpdate:      foreignKeyActions[onUpdate],
+					Deferrable:    deferred,
+				}
+				t.ForeignKeys[name] = fk
+			}
+			fk.LocalColumns = append(fk.LocalColumns, column)
+			fk.ForeignColumns = append(fk.ForeignColumns, foreignColumn.String)
+		}
+	}
+	return rows.Err()
+}
+
+func (i *introspector) readChecks(ctx context.Context) error {
+	rows, err := bunny.Query(ctx, selectChecksSQL)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var oid int64
+		var name, expr string
+		var notValid bool
+		if err := rows.Scan(&oid, &name, &expr, &notValid); err != nil {
+			return err
+		}
+		t, ok := i.tables[oid]
+		if !ok {
+			continue
+		}
+		t.Checks[name] = &schema.Check{
+			Expr:     stripParens(expr),
+			NotValid: notValid,
+		}
+	}
+	return rows.Err()
+}
+
+// stripParens removes the parentheses Postgres adds around check expressions.
+func stripParens(s string) string {
+	if len(s) < 2 || s[0] != '(' || s[len(s)-1] != ')' {
+		return s
+	}
+	depth := 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 && i != len(s)-1 {
+				// The first parenthesis closes before the end, as in "(a) AND (b)".
+				return s
+			}
+		}
+	}
+	return s[1 : len(s)-1]
+}
+
+// foreignKeyActions maps the pg_constraint action codes to actions.
+var foreignKeyActions = map[string]schema.ForeignKeyAction{
+	"a": schema.NoAction,
+	"r": schema.Restrict,
+	"c": schema.Cascade,
+	"n": schema.SetNull,
+	"d": schema.SetDefault,
+}
+
+func (i *introspector) readIndexes(ctx context.Context) error {
+	rows, err := bunny.Query(ctx, selectIndexesSQL)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var oid int64
+		var name, method, column string
+		var where sql.NullString
+		if err := rows.Scan(&oid, &name, &method, &column, &where); err != nil {
+			return err
+		}
+		t, ok := i.tables[oid]
+		if !ok {
+			continue
+		}
+		idx, ok := t.Indexes[name]
+		if !ok {
+			if method == "btree" {
+				method = ""
+			}
+			idx = &schema.Index{
+				Method: method,
+				Where:  where.String,
+			}
+			t.Indexes[name] = idx
+		}
+		idx.Columns = append(idx.Columns, unquoteIdent(column))
+	}
+	return rows.Err()
+}
+
+// unquoteIdent removes the quotes from an identifier quoted by Postgres.
+// Index expressions are returned unchanged.
+func unquoteIdent(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' && !strings.Contains(s[1:len(s)-1], "\"") {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+var (
+	rgxCastedLiteral = regexp.MustCompile(`^('(?:[^']|'')*')::[a-z ]+(?:\(\d+(?:,\d+)?\))?(?:\[\])*$`)
+	rgxNumber        = regexp.MustCompile(`^-?\d+(?:\.\d+)?$`)
+)
+
+// normalizeDefault removes the cast Postgres adds to literal defaults, so
+// 'EUR'::text becomes 'EUR' and '-1'::integer becomes -1. This is the way
+// defaults are written in the models.
+func normalizeDefault(def string) string {
+	m := rgxCastedLiteral.FindStringSubmatch(def)
+	if m == nil {
+		return def
+	}
+	lit := m[1]
+	if inner := lit[1 : len(lit)-1]; rgxNumber.MatchString(inner) {
+		return inner
+	}
+	return lit
+}
@@ -0,0 +1,60 @@
+package introspect
+
+import "testing"
+
+func TestNormalizeDefault(t *testing.T) {
+	tests := []struct {
+		def  string
+		want string
+	}{
+		{"'EUR'::text", "'EUR'"},
+		{"''::text", "''"},
+		{"'it''s'::character varying", "'it''s'"},
+		{"'-1'::integer", "-1"},
+		{"'1.5'::numeric(10,2)", "1.5"},
+		{"'{}'::text[]", "'{}'"},
+		{"now()", "now()"},
+		{"nextval('s'::regclass)", "nextval('s'::regclass)"},
+		{"0", "0"},
+	}
+	for _, tt := range tests {
+		if got := normalizeDefault(tt.def); got != tt.want {
+			t.Errorf("normalizeDefault(%q) = %q, want %q", tt.def, got, tt.want)
+		}
+	}
+}
+
+func TestStripParens(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"(seq >= 0)", "seq >= 0"},
+		{"((a > 0) AND (b > 0))", "(a > 0) AND (b > 0)"},
+		{"(a > 0) AND (b > 0)", "(a > 0) AND (b > 0)"},
+		{"a > 0", "a > 0"},
+		{"()", ""},
+	}
+	for _, tt := range tests {
+		if got := stripParens(tt.expr); got != tt.want {
+			t.Errorf("stripParens(%q) = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestUnquoteIdent(t *testing.T) {
+	tests := []struct {
+		ident string
+		want  string
+	}{
+		{`"order"`, "order"},
+		{"title", "title"},
+		{`lower("title")`, `lower("title")`},
+		{`"a"||"b"`, `"a"||"b"`},
+	}
+	for _, tt := range tests {
+		if got := unquoteIdent(tt.ident); got != tt.want {
+			t.Errorf("unquoteIdent(%q) = %q, want %q", tt.ident, got, tt.want)
+		}
+	}
+}
@@ -45,3 +45,31 @@ func (o AlterTable) Apply(d *schema.Database) error {
 	}
 	return nil
 }
+
+func (o AlterTable) Reverse(d *schema.Database) ([]Operation, error) {
+	t, err := getTable(d, o.SchemaName, o.TableName)
+	if err != nil {
+		return nil, err
+	}
+	t = t.Clone()
+
+	var ops []AlterTableSuboperation
+	for _, op := range o.Ops {
+		rev, err := op.Reverse(d, t, o)
+		if err != nil {
+			return nil, fmt.Errorf("%T on table %s: %w", op, o.TableName, err)
+		}
+		if err := op.Apply(d, t, o); err != nil {
+			return nil, fmt.Errorf("%T on table %s: %w", op, o.TableName, err)
+		}
+		ops = append(rev, ops...)
+	}
+	if len(ops) == 0 {
+		return nil, nil
+	}
+	return []Operation{AlterTable{
+		SchemaName: o.SchemaName,
+		TableName:  o.TableName,
+		Ops:        ops,
+	}}, nil
+}
@@ -9,6 +9,10 @@ import (
 type AlterTableSuboperation interface {
 	GetAlterTableSQL(ato *AlterTable) string
 	Apply(d *schema.Database, t *schema.Table, ato AlterTable) error
+
+	// Reverse returns the suboperations that undo this suboperation. t is the
+	// table state before this suboperation is applied. It is not modified.
+	Reverse(d *schema.Database, t *schema.Table, ato AlterTable) ([]AlterTableSuboperation, error)
 }
 
 type AlterTableAddColumn struct {
@@ -39,10 +43,15 @@ func (o AlterTableAddColumn) Apply(d *schema.Database, t *schema.Table, ato Alte
 		Type:     o.Type,
 		Default:  o.Default,
 		Nullable: o.Nullable,
+		Position: t.NextColumnPosition(),
 	}
 	return nil
 }
 
+func (o AlterTableAddColumn) Reverse(d *schema.Database, t *schema.Table, ato AlterTable) ([]AlterTableSuboperation, error) {
+	return []AlterTableSuboperation{AlterTableDropColumn{Name: o.Name}}, nil
+}
+
 type AlterTableDropColumn struct {
 	Name string
 }
@@ -59,6 +68,10 @@ func (o AlterTableDropColumn) Apply(d *schema.Database, t *schema.Table, ato Alt
 	return nil
 }
 
+func (o AlterTableDropColumn) Reverse(d *schema.Database, t *schema.Table, ato AlterTable) ([]AlterTableSuboperation, error) {
+	return nil, fmt.Errorf("dropping column %s loses its data: %w", o.Name, ErrIrreversible)
+}
+
 type AlterTableCreatePrimaryKey struct {
 	Columns []string
 }
@@ -77,6 +90,10 @@ func (o AlterTableCreatePrimaryKey) Apply(d *schema.Database, t *schema.Table, a
 	return nil
 }
 
+func (o AlterTableCreatePrimaryKey) Reverse(d *schema.Database, t *schema.Table, ato AlterTable) ([]AlterTableSuboperation, error) {
+	return []AlterTableSuboperation{AlterTableDropPrimaryKey{}}, nil
+}
+
 type AlterTableDropPrimaryKey struct {
 }
 
@@ -92,6 +109,15 @@ func (o AlterTableDropPrimaryKey) Apply(d *schema.Database, t *schema.Table, ato
 	return nil
 }
 
+func (o AlterTableDropPrimaryKey) Reverse(d *schema.Database, t *schema.Table, ato AlterTable) ([]AlterTableSuboperation, error) {
+	if t.PrimaryKey == nil {
+		return nil, fmt.Errorf("table does not have a primary key")
+	}
+	return []AlterTableSuboperation{AlterTableCreatePrimaryKey{
+		Columns: t.PrimaryKey.Columns,
+	}}, nil
+}
+
 type AlterTableCreateUnique struct {
 	Name    string
 	Columns []string
@@ -111,6 +137,10 @@ func (o AlterTableCreateUnique) Apply(d *schema.Database, t *schema.Table, ato A
 	return nil
 }
 
+func (o AlterTableCreateUnique) Reverse(d *schema.Database, t *schema.Table, ato AlterTable) ([]AlterTableSuboperation, error) {
+	return []AlterTableSuboperation{AlterTableDropUnique{Name: o.Name}}, nil
+}
+
 type AlterTableDropUnique struct {
 	Name string
 }
@@ -127,12 +157,26 @@ func (o AlterTableDropUnique) Apply(d *schema.Database, t *schema.Table, ato Alt
 	return nil
 }
 
+func (o AlterTableDropUnique) Reverse(d *schema.Database, t *schema.Table, ato AlterTable) ([]AlterTableSuboperation, error) {
+	u, ok := t.Uniques[o.Name]
+	if !ok {
+		return nil, fmt.Errorf("no such unique: %s ", o.Name)
+	}
+	return []AlterTableSuboperation{AlterTableCreateUnique{
+		Name:    o.Name,
+		Columns: u.Columns,
+	}}, nil
+}
+
 type AlterTableCreateForeignKey struct {
 	Name           string
 	Columns        []string
 	ForeignSchema  string
 	ForeignTable   string
 	ForeignColumns []string
+	OnDelete       schema.ForeignKeyAction
+	OnUpdate       schema.ForeignKeyAction
+	Deferrable     bool
 	// NotValid adds the constraint with `NOT VALID`, skipping the
 	// full-table validation scan. The constraint is enforced on all
 	// subsequent writes; existing rows are not checked until a later
@@ -143,6 +187,15 @@ type AlterTableCreateForeignKey struct {
 
 func (o AlterTableCreateForeignKey) GetAlterTableSQL(ato *AlterTable) string {
 	sql := fmt.Sprintf("ADD CONSTRAINT \"%s\" FOREIGN KEY (%s) REFERENCES %s (%s)", o.Name, columnList(o.Columns), sqlName(o.ForeignSchema, o.ForeignTable), columnList(o.ForeignColumns))
+	if o.OnDelete != schema.NoAction {
+		sql += " ON DELETE " + string(o.OnDelete)
+	}
+	if o.OnUpdate != schema.NoAction {
+		sql += " ON UPDATE " + string(o.OnUpdate)
+	}
+	if o.Deferrable {
+		sql += " DEFERRABLE INITIALLY DEFERRED"
+	}
 	if o.NotValid {
 		sql += " NOT VALID"
 	}
@@ -158,12 +211,20 @@ func (o AlterTableCreateForeignKey) Apply(d *schema.Database, t *schema.Table, a
 	}
 	t.ForeignKeys[o.Name] = &schema.ForeignKey{
 		LocalColumns:   o.Columns,
+		ForeignSchema:  o.ForeignSchema,
 		ForeignTable:   o.ForeignTable,
 		ForeignColumns: o.ForeignColumns,
+		OnDelete:       o.OnDelete,
+		OnUpdate:       o.OnUpdate,
+		Deferrable:     o.Deferrable,
 	}
 	return nil
 }
 
+func (o AlterTableCreateForeignKey) Reverse(d *schema.Database, t *schema.Table, ato AlterTable) ([]AlterTableSuboperation, error) {
+	return []AlterTableSuboperation{AlterTableDropForeignKey{Name: o.Name}}, nil
+}
+
 type AlterTableDropForeignKey struct {
 	Name string
 }
@@ -180,6 +241,23 @@ func (o AlterTableDropForeignKey) Apply(d *schema.Database, t *schema.Table, ato
 	return nil
 }
 
+func (o AlterTableDropForeignKey) Reverse(d *schema.Database, t *schema.Table, ato AlterTable) ([]AlterTableSuboperation, error) {
+	fk, ok := t.ForeignKeys[o.Name]
+	if !ok {
+		return nil, fmt.Errorf("no such foreign key: %s", o.Name)
+	}
+	return []AlterTableSuboperation{AlterTableCreateForeignKey{
+		Name:           o.Name,
+		Columns:        fk.LocalColumns,
+		ForeignSchema:  fk.ForeignSchema,
+		ForeignTable:   fk.ForeignTable,
+		ForeignColumns: fk.ForeignColumns,
+		OnDelete:       fk.OnDelete,
+		OnUpdate:       fk.OnUpdate,
+		Deferrable:     fk.Deferrable,
+	}}, nil
+}
+
 type AlterTableSetNotNull struct {
 	Name string
 }
@@ -197,6 +275,17 @@ func (o AlterTableSetNotNull) Apply(d *schema.Database, t *schema.Table, ato Alt
 	return nil
 }
 
+func (o AlterTableSetNotNull) Reverse(d *schema.Database, t *schema.Table, ato AlterTable) ([]AlterTableSuboperation, error) {
+	c, ok := t.Columns[o.Name]
+	if !ok {
+		return nil, fmt.Errorf("no such column: %s ", o.Name)
+	}
+	if !c.Nullable {
+		return nil, nil
+	}
+	return []AlterTableSuboperation{AlterTableSetNull{Name: o.Name}}, nil
+}
+
 type AlterTableSetNull struct {
 	Name string
 }
@@ -214,6 +303,17 @@ func (o AlterTableSetNull) Apply(d *schema.Database, t *schema.Table, ato AlterT
 	return nil
 }
 
+func (o AlterTableSetNull) Reverse(d *schema.Database, t *schema.Table, ato AlterTable) ([]AlterTableSuboperation, error) {
+	c, ok := t.Columns[o.Name]
+	if !ok {
+		return nil, fmt.Errorf("no such column: %s ", o.Name)
+	}
+	if c.Nullable {
+		return nil, nil
+	}
+	return []AlterTableSuboperation{AlterTableSetNotNull{Name: o.Name}}, nil
+}
+
 type AlterTableSetDefault struct {
 	Name    string
 	Default string
@@ -232,6 +332,14 @@ func (o AlterTableSetDefault) Apply(d *schema.Database, t *schema.Table, ato Alt
 	return nil
 }
 
+func (o AlterTableSetDefault) Reverse(d *schema.Database, t *schema.Table, ato AlterTable) ([]AlterTableSuboperation, error) {
+	c, ok := t.Columns[o.Name]
+	if !ok {
+		return nil, fmt.Errorf("no such column: %s ", o.Name)
+	}
+	return []AlterTableSuboperation{restoreDefault(o.Name, c.Default)}, nil
+}
+
 type AlterTableDropDefault struct {
 	Name string
 }
@@ -249,6 +357,17 @@ func (o AlterTableDropDefault) Apply(d *schema.Database, t *schema.Table, ato Al
 	return nil
 }
 
+func (o AlterTableDropDefault) Reverse(d *schema.Database, t *schema.Table, ato AlterTable) ([]AlterTableSuboperation, error) {
+	c, ok := t.Columns[o.Name]
+	if !ok {
+		return nil, fmt.Errorf("no such column: %s ", o.Name)
+	}
+	if c.Default == "" {
+		return nil, nil
+	}
+	return []AlterTableSuboperation{restoreDefault(o.Name, c.Default)}, nil
+}
+
 type AlterTableSetType struct {
 	Name string
 	Type string
@@ -266,3 +385,113 @@ func (o AlterTableSetType) Apply(d *schema.Database, t *schema.Table, ato AlterT
 	c.Type = o.Type
 	return nil
 }
+
+func (o AlterTableSetType) Reverse(d *schema.Database, t *schema.Table, ato AlterTable) ([]AlterTableSuboperation, error) {
+	c, ok := t.Columns[o.Name]
+	if !ok {
+		return nil, fmt.Errorf("no such column: %s ", o.Name)
+	}
+	return []AlterTableSuboperation{AlterTableSetType{
+		Name: o.Name,
+		Type: c.Type,
+	}}, nil
+}
+
+// restoreDefault returns the suboperation that sets the default of a column
+// back to def, dropping it if def is empty.
+func restoreDefault(name string, def string) AlterTableSuboperation {
+	if def == "" {
+		return AlterTableDropDefault{Name: name}
+	}
+	return AlterTableSetDefault{
+		Name:    name,
+		Default: def,
+	}
+}
+
+type AlterTableCreateCheck struct {
+	Name string
+	Expr string
+	// NotValid adds the constraint with `NOT VALID`, like
+	// AlterTableCreateForeignKey.NotValid. Existing rows are checked later
+	// by AlterTableValidateConstraint.
+	NotValid bool
+}
+
+func (o AlterTableCreateCheck) GetAlterTableSQL(ato *AlterTable) string {
+	sql := fmt.Sprintf("ADD CONSTRAINT \"%s\" CHECK (%s)", o.Name, o.Expr)
+	if o.NotValid {
+		sql += " NOT VALID"
+	}
+	return sql
+}
+
+func (o AlterTableCreateCheck) Apply(d *schema.Database, t *schema.Table, ato AlterTable) error {
+	if _, ok := t.Checks[o.Name]; ok {
+		return fmt.Errorf("check already exists: %s ", o.Name)
+	}
+	t.Checks[o.Name] = &schema.Check{
+		Expr:     o.Expr,
+		NotValid: o.NotValid,
+	}
+	return nil
+}
+
+func (o AlterTableCreateCheck) Reverse(d *schema.Database, t *schema.Table, ato AlterTable) ([]AlterTableSuboperation, error) {
+	return []AlterTableSuboperation{AlterTableDropCheck{Name: o.Name}}, nil
+}
+
+type AlterTableDropCheck struct {
+	Name string
+}
+
+func (o AlterTableDropCheck) GetAlterTableSQL(ato *AlterTable) string {
+	return fmt.Sprintf("DROP CONSTRAINT \"%s\"", o.Name)
+}
+
+func (o AlterTableDropCheck) Apply(d *schema.Database, t *schema.Table, ato AlterTable) error {
+	if _, ok := t.Checks[o.Name]; !ok {
+		return fmt.Errorf("no such check: %s ", o.Name)
+	}
+	delete(t.Checks, o.Name)
+	return nil
+}
+
+func (o AlterTableDropCheck) Reverse(d *schema.Database, t *schema.Table, ato AlterTable) ([]AlterTableSuboperation, error) {
+	c, ok := t.Checks[o.Name]
+	if !ok {
+		return nil, fmt.Errorf("no such check: %s ", o.Name)
+	}
+	return []AlterTableSuboperation{AlterTableCreateCheck{
+		Name:     o.Name,
+		Expr:     c.Expr,
+		NotValid: c.NotValid,
+	}}, nil
+}
+
+// AlterTableValidateConstraint checks the existing rows against a check or
+// foreign key constraint added NOT VALID.
+type AlterTableValidateConstraint struct {
+	Name string
+}
+
+func (o AlterTableValidateConstraint) GetAlterTableSQL(ato *AlterTable) string {
+	return fmt.Sprintf("VALIDATE CONSTRAINT \"%s\"", o.Name)
+}
+
+func (o AlterTableValidateConstraint) Apply(d *schema.Database, t *schema.Table, ato AlterTable) error {
+	if c, ok := t.Checks[o.Name]; ok {
+		c.NotValid = false
+		return nil
+	}
+	if _, ok := t.ForeignKeys[o.Name]; ok {
+		return nil
+	}
+	return fmt.Errorf("no such check or foreign key: %s ", o.Name)
+}
+
+// Reverse does nothing: a constraint can't be made not valid again, and
+// leaving it validated doesn't change what the schema allows.
+func (o AlterTableValidateConstraint) Reverse(d *schema.Database, t *schema.Table, ato AlterTable) ([]AlterTableSuboperation, error) {
+	return nil, nil
+}
@@ -29,6 +29,11 @@ func (o CreateIndex) GetSQL() string {
 	return buf.String()
 }
 
+// Transactional returns false, since CREATE INDEX CONCURRENTLY can't run inside a transaction.
+func (o CreateIndex) Transactional() bool {
+	return false
+}
+
 func (o CreateIndex) Apply(d *schema.Database) error {
 	s, ok := d.Schemas[o.SchemaName]
 	if !ok {
@@ -48,3 +53,11 @@ func (o CreateIndex) Apply(d *schema.Database) error {
 	}
 	return nil
 }
+
+func (o CreateIndex) Reverse(d *schema.Database) ([]Operation, error) {
+	return []Operation{DropIndex{
+		SchemaName: o.SchemaName,
+		TableName:  o.TableName,
+		IndexName:  o.IndexName,
+	}}, nil
+}
@@ -21,3 +21,7 @@ func (o CreateSchema) Apply(d *schema.Database) error {
 	d.Schemas[o.SchemaName] = schema.NewSchema()
 	return nil
 }
+
+func (o CreateSchema) Reverse(d *schema.Database) ([]Operation, error) {
+	return []Operation{DropSchema{SchemaName: o.SchemaName}}, nil
+}
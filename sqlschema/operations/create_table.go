@@ -46,14 +46,22 @@ func (o CreateTable) Apply(d *schema.Database) error {
 	}
 
 	t := schema.NewTable()
-	for _, c := range o.Columns {
+	for i, c := range o.Columns {
 		t.Columns[c.Name] = &schema.Column{
 			Nullable: c.Nullable,
 			Type:     c.Type,
 			Default:  c.Default,
+			Position: i,
 		}
 	}
 	s.Tables[o.TableName] = t
 
 	return nil
 }
+
+func (o CreateTable) Reverse(d *schema.Database) ([]Operation, error) {
+	return []Operation{DropTable{
+		SchemaName: o.SchemaName,
+		TableName:  o.TableName,
+	}}, nil
+}
@@ -31,3 +31,22 @@ func (o DropIndex) Apply(d *schema.Database) error {
 	delete(t.Indexes, o.IndexName)
 	return nil
 }
+
+func (o DropIndex) Reverse(d *schema.Database) ([]Operation, error) {
+	t, err := getTable(d, o.SchemaName, o.TableName)
+	if err != nil {
+		return nil, err
+	}
+	i, ok := t.Indexes[o.IndexName]
+	if !ok {
+		return nil, fmt.Errorf("no such index: %s", o.IndexName)
+	}
+	return []Operation{CreateIndex{
+		SchemaName: o.SchemaName,
+		TableName:  o.TableName,
+		IndexName:  o.IndexName,
+		Columns:    i.Columns,
+		Method:     i.Method,
+		Where:      i.Where,
+	}}, nil
+}
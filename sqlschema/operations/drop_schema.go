@@ -21,3 +21,14 @@ func (o DropSchema) Apply(d *schema.Database) error {
 	delete(d.Schemas, o.SchemaName)
 	return nil
 }
+
+func (o DropSchema) Reverse(d *schema.Database) ([]Operation, error) {
+	s, ok := d.Schemas[o.SchemaName]
+	if !ok {
+		return nil, fmt.Errorf("no such schema: %s", o.SchemaName)
+	}
+	if len(s.Tables) != 0 {
+		return nil, fmt.Errorf("dropping non-empty schema %s loses its tables: %w", o.SchemaName, ErrIrreversible)
+	}
+	return []Operation{CreateSchema{SchemaName: o.SchemaName}}, nil
+}
@@ -25,3 +25,7 @@ func (o DropTable) Apply(d *schema.Database) error {
 	delete(s.Tables, o.TableName)
 	return nil
 }
+
+func (o DropTable) Reverse(d *schema.Database) ([]Operation, error) {
+	return nil, fmt.Errorf("dropping table %s loses its data: %w", o.TableName, ErrIrreversible)
+}
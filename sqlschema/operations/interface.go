@@ -1,10 +1,68 @@
 package operations
 
 import (
+	"errors"
+
 	"github.com/sqlbunny/sqlbunny/sqlschema/schema"
 )
 
+// ErrIrreversible is returned by Reverse for operations that can't be undone,
+// usually because undoing them would need data that the operation destroyed.
+var ErrIrreversible = errors.New("operation is irreversible")
+
 type Operation interface {
 	GetSQL() string
 	Apply(d *schema.Database) error
+
+	// Reverse returns the operations that undo this operation. d is the
+	// database state before this operation is applied. It is not modified.
+	Reverse(d *schema.Database) ([]Operation, error)
 }
+
+// TransactionalOperation is implemented by operations that may not be able to
+// run inside a transaction, such as concurrent index builds. Operations that
+// don't implement it can always run inside a transaction.
+type TransactionalOperation interface {
+	Transactional() bool
+}
+
+// IsTransactional returns whether op can run inside a transaction.
+func IsTransactional(op Operation) bool {
+	if t, ok := op.(TransactionalOperation); ok {
+		return t.Transactional()
+	}
+	return true
+}
+
+// ReverseAll returns the operations that undo all of ops, in the order they
+// must be run. d is the database state before ops are applied. It is not modified.
+func ReverseAll(d *schema.Database, ops []Operation) ([]Operation, error) {
+	d = d.Clone()
+
+	var res []Operation
+	for _, op := range ops {
+		rev, err := op.Reverse(d)
+		if err != nil {
+			return nil, err
+		}
+		if err := op.Apply(d); err != nil {
+			return nil, err
+		}
+		res = append(rev, res...)
+	}
+	return res, nil
+}
+
+var _ Operation = AlterTable{}
+var _ Operation = CreateIndex{}
+var _ Operation = CreateSchema{}
+var _ Operation = CreateTable{}
+var _ Operation = DropIndex{}
+var _ Operation = DropSchema{}
+var _ Operation = DropTable{}
+var _ Operation = RenameColumn{}
+var _ Operation = RenameConstraint{}
+var _ Operation = RenameIndex{}
+var _ Operation = RenameTable{}
+var _ Operation = SetTableSchema{}
+var _ Operation = SQL{}
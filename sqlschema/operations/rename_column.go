@@ -80,3 +80,12 @@ func (o RenameColumn) Apply(d *schema.Database) error {
 	}
 	return nil
 }
+
+func (o RenameColumn) Reverse(d *schema.Database) ([]Operation, error) {
+	return []Operation{RenameColumn{
+		SchemaName:    o.SchemaName,
+		TableName:     o.TableName,
+		OldColumnName: o.NewColumnName,
+		NewColumnName: o.OldColumnName,
+	}}, nil
+}
@@ -0,0 +1,78 @@
+package operations
+
+import (
+	"fmt"
+
+	"github.com/sqlbunny/sqlbunny/sqlschema/schema"
+)
+
+// ConstraintKind is the kind of constraint renamed by RenameConstraint.
+type ConstraintKind string
+
+const (
+	UniqueConstraint     ConstraintKind = "unique"
+	ForeignKeyConstraint ConstraintKind = "foreign_key"
+	CheckConstraint      ConstraintKind = "check"
+	// PrimaryKeyConstraint is the primary key, whose name isn't part of the schema.
+	PrimaryKeyConstraint ConstraintKind = "primary_key"
+)
+
+// RenameConstraint renames a constraint of the given Kind.
+type RenameConstraint struct {
+	SchemaName        string
+	TableName         string
+	Kind              ConstraintKind
+	OldConstraintName string
+	NewConstraintName string
+}
+
+func (o RenameConstraint) GetSQL() string {
+	return fmt.Sprintf("ALTER TABLE %s RENAME CONSTRAINT \"%s\" TO \"%s\"", sqlName(o.SchemaName, o.TableName), o.OldConstraintName, o.NewConstraintName)
+}
+
+func (o RenameConstraint) Apply(d *schema.Database) error {
+	t, err := getTable(d, o.SchemaName, o.TableName)
+	if err != nil {
+		return err
+	}
+
+	switch o.Kind {
+	case UniqueConstraint:
+		return renameKey(t.Uniques, o.TableName, o.OldConstraintName, o.NewConstraintName)
+	case ForeignKeyConstraint:
+		return renameKey(t.ForeignKeys, o.TableName, o.OldConstraintName, o.NewConstraintName)
+	case CheckConstraint:
+		return renameKey(t.Checks, o.TableName, o.OldConstraintName, o.NewConstraintName)
+	case PrimaryKeyConstraint:
+		if t.PrimaryKey == nil {
+			return fmt.Errorf("no primary key on table %s", o.TableName)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown constraint kind: %q", o.Kind)
+	}
+}
+
+// renameKey moves the constraint oldName of m to newName.
+func renameKey[T any](m map[string]T, table, oldName, newName string) error {
+	c, ok := m[oldName]
+	if !ok {
+		return fmt.Errorf("no such constraint on table %s: %s", table, oldName)
+	}
+	if _, ok := m[newName]; ok {
+		return fmt.Errorf("destination constraint already exists: %s", newName)
+	}
+	delete(m, oldName)
+	m[newName] = c
+	return nil
+}
+
+func (o RenameConstraint) Reverse(d *schema.Database) ([]Operation, error) {
+	return []Operation{RenameConstraint{
+		SchemaName:        o.SchemaName,
+		TableName:         o.TableName,
+		Kind:              o.Kind,
+		OldConstraintName: o.NewConstraintName,
+		NewConstraintName: o.OldConstraintName,
+	}}, nil
+}
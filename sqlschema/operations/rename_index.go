@@ -0,0 +1,44 @@
+package operations
+
+import (
+	"fmt"
+
+	"github.com/sqlbunny/sqlbunny/sqlschema/schema"
+)
+
+type RenameIndex struct {
+	SchemaName   string
+	TableName    string
+	OldIndexName string
+	NewIndexName string
+}
+
+func (o RenameIndex) GetSQL() string {
+	return fmt.Sprintf("ALTER INDEX %s RENAME TO \"%s\"", sqlName(o.SchemaName, o.OldIndexName), o.NewIndexName)
+}
+
+func (o RenameIndex) Apply(d *schema.Database) error {
+	t, err := getTable(d, o.SchemaName, o.TableName)
+	if err != nil {
+		return err
+	}
+	i, ok := t.Indexes[o.OldIndexName]
+	if !ok {
+		return fmt.Errorf("no such index: %s", o.OldIndexName)
+	}
+	if _, ok := t.Indexes[o.NewIndexName]; ok {
+		return fmt.Errorf("destination index already exists: %s", o.NewIndexName)
+	}
+	delete(t.Indexes, o.OldIndexName)
+	t.Indexes[o.NewIndexName] = i
+	return nil
+}
+
+func (o RenameIndex) Reverse(d *schema.Database) ([]Operation, error) {
+	return []Operation{RenameIndex{
+		SchemaName:   o.SchemaName,
+		TableName:    o.TableName,
+		OldIndexName: o.NewIndexName,
+		NewIndexName: o.OldIndexName,
+	}}, nil
+}
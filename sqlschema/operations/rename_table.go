@@ -41,3 +41,11 @@ func (o RenameTable) Apply(d *schema.Database) error {
 	}
 	return nil
 }
+
+func (o RenameTable) Reverse(d *schema.Database) ([]Operation, error) {
+	return []Operation{RenameTable{
+		SchemaName:   o.SchemaName,
+		TableName:    o.NewTableName,
+		NewTableName: o.TableName,
+	}}, nil
+}
@@ -45,3 +45,11 @@ func (o SetTableSchema) Apply(d *schema.Database) error {
 	}
 	return nil
 }
+
+func (o SetTableSchema) Reverse(d *schema.Database) ([]Operation, error) {
+	return []Operation{SetTableSchema{
+		SchemaName:    o.NewSchemaName,
+		TableName:     o.TableName,
+		NewSchemaName: o.SchemaName,
+	}}, nil
+}
@@ -1,18 +1,41 @@
 package operations
 
 import (
+	"fmt"
+
 	"github.com/sqlbunny/sqlbunny/sqlschema/schema"
 )
 
 type SQL struct {
 	SQL string
+	// ReverseSQL undoes SQL when the migration is rolled back.
+	// If empty, the operation is irreversible.
+	ReverseSQL string
+	// NonTransactional must be set if SQL can't run inside a transaction,
+	// for example `CREATE INDEX CONCURRENTLY`.
+	NonTransactional bool
 }
 
 func (o SQL) GetSQL() string {
 	return o.SQL
 }
 
+func (o SQL) Transactional() bool {
+	return !o.NonTransactional
+}
+
 func (o SQL) Apply(d *schema.Database) error {
 	// do nothing.
 	return nil
 }
+
+func (o SQL) Reverse(d *schema.Database) ([]Operation, error) {
+	if o.ReverseSQL == "" {
+		return nil, fmt.Errorf("SQL operation has no ReverseSQL: %w", ErrIrreversible)
+	}
+	return []Operation{SQL{
+		SQL:              o.ReverseSQL,
+		ReverseSQL:       o.SQL,
+		NonTransactional: o.NonTransactional,
+	}}, nil
+}
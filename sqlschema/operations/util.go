@@ -3,6 +3,8 @@ package operations
 import (
 	"bytes"
 	"fmt"
+
+	"github.com/sqlbunny/sqlbunny/sqlschema/schema"
 )
 
 func sqlName(schema, name string) string {
@@ -24,3 +26,15 @@ func columnList(columns []string) string {
 	}
 	return buf.String()
 }
+
+func getTable(d *schema.Database, schemaName, tableName string) (*schema.Table, error) {
+	s, ok := d.Schemas[schemaName]
+	if !ok {
+		return nil, fmt.Errorf("no such schema: %s", schemaName)
+	}
+	t, ok := s.Tables[tableName]
+	if !ok {
+		return nil, fmt.Errorf("no such table: %s", tableName)
+	}
+	return t, nil
+}
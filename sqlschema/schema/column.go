@@ -1,8 +1,71 @@
 package schema
 
+import "strings"
+
 // Column holds information about a database table column.
 type Column struct {
 	Type     string
 	Default  string
 	Nullable bool
+
+	// Position orders the columns of a table, lowest first. It's only used to
+	// create columns in a stable order, columns at different positions are
+	// otherwise the same.
+	Position int
+}
+
+func (c *Column) Clone() *Column {
+	c2 := *c
+	return &c2
+}
+
+// typeAliases maps Postgres type aliases to the name format_type returns for them.
+var typeAliases = map[string]string{
+	"int":         "integer",
+	"int2":        "smallint",
+	"int4":        "integer",
+	"int8":        "bigint",
+	"float4":      "real",
+	"float8":      "double precision",
+	"bool":        "boolean",
+	"varchar":     "character varying",
+	"timestamp":   "timestamp without time zone",
+	"timestamptz": "timestamp with time zone",
+	"time":        "time without time zone",
+	"timetz":      "time with time zone",
+}
+
+// NormalizeType returns the canonical name of a Postgres type, as returned
+// by format_type. Two type names are the same type if they normalize to the
+// same string.
+func NormalizeType(t string) string {
+	t = strings.ToLower(strings.TrimSpace(t))
+	suffix := ""
+	for strings.HasSuffix(t, "[]") {
+		t = strings.TrimSpace(strings.TrimSuffix(t, "[]"))
+		suffix += "[]"
+	}
+
+	// Split the modifier off, like "(3)" in "timestamp(3) with time zone".
+	name, mod := t, ""
+	if i := strings.IndexByte(t, '('); i != -1 {
+		if j := strings.IndexByte(t[i:], ')'); j != -1 {
+			name = strings.TrimSpace(t[:i] + " " + t[i+j+1:])
+			mod = strings.ReplaceAll(t[i:i+j+1], " ", "")
+		}
+	}
+	name = strings.Join(strings.Fields(name), " ")
+	if a, ok := typeAliases[name]; ok {
+		name = a
+	}
+
+	// format_type puts the precision of the time types before the time zone.
+	if mod != "" {
+		for _, base := range []string{"timestamp ", "time "} {
+			if strings.HasPrefix(name, base) && strings.HasSuffix(name, " time zone") {
+				return strings.TrimSpace(base) + mod + " " + name[len(base):] + suffix
+			}
+		}
+	}
+	return name + mod + suffix
 }
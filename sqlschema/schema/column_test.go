@@ -0,0 +1,28 @@
+package schema
+
+import "testing"
+
+func TestNormalizeType(t *testing.T) {
+	tests := []struct {
+		typ  string
+		want string
+	}{
+		{"text", "text"},
+		{"INT4", "integer"},
+		{"int8[]", "bigint[]"},
+		{"varchar(10)", "character varying(10)"},
+		{"numeric(10, 2)", "numeric(10,2)"},
+		{"timestamptz", "timestamp with time zone"},
+		{"timestamptz(3)", "timestamp(3) with time zone"},
+		{"timestamp(3)", "timestamp(3) without time zone"},
+		{"timestamp(3) with time zone", "timestamp(3) with time zone"},
+		{"timestamp with time zone", "timestamp with time zone"},
+		{"timetz(6)[]", "time(6) with time zone[]"},
+		{"time(0)", "time(0) without time zone"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeType(tt.typ); got != tt.want {
+			t.Errorf("NormalizeType(%q) = %q, want %q", tt.typ, got, tt.want)
+		}
+	}
+}
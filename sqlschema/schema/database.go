@@ -9,3 +9,12 @@ func NewDatabase() *Database {
 		Schemas: make(map[string]*Schema),
 	}
 }
+
+// Clone returns a deep copy of the database.
+func (d *Database) Clone() *Database {
+	d2 := NewDatabase()
+	for name, s := range d.Schemas {
+		d2.Schemas[name] = s.Clone()
+	}
+	return d2
+}
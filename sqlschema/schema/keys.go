@@ -17,10 +17,75 @@ type Unique struct {
 	Columns []string
 }
 
+// Check represents a check constraint in a database
+type Check struct {
+	Expr     string // Boolean expression every row must satisfy.
+	NotValid bool   // The constraint was added NOT VALID, existing rows haven't been checked.
+}
+
+// ForeignKeyAction is the referential action of a foreign key, run when the
+// referenced row is deleted or updated.
+type ForeignKeyAction string
+
+const (
+	NoAction   ForeignKeyAction = "" // Default. Fail if the row is still referenced at the end of the statement.
+	Restrict   ForeignKeyAction = "RESTRICT"
+	Cascade    ForeignKeyAction = "CASCADE"
+	SetNull    ForeignKeyAction = "SET NULL"
+	SetDefault ForeignKeyAction = "SET DEFAULT"
+)
+
 // ForeignKey represents a foreign key constraint in a database
 type ForeignKey struct {
 	LocalColumns   []string
 	ForeignSchema  string
 	ForeignTable   string
 	ForeignColumns []string
+	OnDelete       ForeignKeyAction
+	OnUpdate       ForeignKeyAction
+	Deferrable     bool // DEFERRABLE INITIALLY DEFERRED: the constraint is checked at the end of the transaction.
+}
+
+func cloneStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append([]string(nil), s...)
+}
+
+func (k *PrimaryKey) Clone() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: cloneStrings(k.Columns),
+	}
+}
+
+func (k *Index) Clone() *Index {
+	return &Index{
+		Columns: cloneStrings(k.Columns),
+		Method:  k.Method,
+		Where:   k.Where,
+	}
+}
+
+func (k *Unique) Clone() *Unique {
+	return &Unique{
+		Columns: cloneStrings(k.Columns),
+	}
+}
+
+func (k *ForeignKey) Clone() *ForeignKey {
+	return &ForeignKey{
+		LocalColumns:   cloneStrings(k.LocalColumns),
+		ForeignSchema:  k.ForeignSchema,
+		ForeignTable:   k.ForeignTable,
+		ForeignColumns: cloneStrings(k.ForeignColumns),
+		OnDelete:       k.OnDelete,
+		OnUpdate:       k.OnUpdate,
+		Deferrable:     k.Deferrable,
+	}
+}
+
+func (k *Check) Clone() *Check {
+	k2 := *k
+	return &k2
 }
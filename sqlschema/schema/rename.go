@@ -0,0 +1,17 @@
+package schema
+
+// TableRename is a table renamed from OldName to NewName.
+type TableRename struct {
+	SchemaName string
+	OldName    string
+	NewName    string
+}
+
+// ColumnRename is a column renamed from OldName to NewName.
+// TableName is the new name of the table, if it is renamed too.
+type ColumnRename struct {
+	SchemaName string
+	TableName  string
+	OldName    string
+	NewName    string
+}
@@ -9,3 +9,11 @@ func NewSchema() *Schema {
 		Tables: make(map[string]*Table),
 	}
 }
+
+func (s *Schema) Clone() *Schema {
+	s2 := NewSchema()
+	for name, t := range s.Tables {
+		s2.Tables[name] = t.Clone()
+	}
+	return s2
+}
@@ -1,5 +1,7 @@
 package schema
 
+import "sort"
+
 // Table represents a database table.
 type Table struct {
 	Columns map[string]*Column `json:"columns"`
@@ -8,6 +10,7 @@ type Table struct {
 	Indexes     map[string]*Index      `json:"indexes"`
 	Uniques     map[string]*Unique     `json:"uniques"`
 	ForeignKeys map[string]*ForeignKey `json:"foreign_keys"`
+	Checks      map[string]*Check      `json:"checks"`
 }
 
 func NewTable() *Table {
@@ -16,5 +19,56 @@ func NewTable() *Table {
 		Indexes:     make(map[string]*Index),
 		Uniques:     make(map[string]*Unique),
 		ForeignKeys: make(map[string]*ForeignKey),
+		Checks:      make(map[string]*Check),
+	}
+}
+
+// ColumnNames returns the names of the columns, ordered by position and then by name.
+func (t *Table) ColumnNames() []string {
+	res := make([]string, 0, len(t.Columns))
+	for name := range t.Columns {
+		res = append(res, name)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		pi, pj := t.Columns[res[i]].Position, t.Columns[res[j]].Position
+		if pi != pj {
+			return pi < pj
+		}
+		return res[i] < res[j]
+	})
+	return res
+}
+
+// NextColumnPosition returns the position for a column added after all the existing ones.
+func (t *Table) NextColumnPosition() int {
+	next := 0
+	for _, c := range t.Columns {
+		if c.Position >= next {
+			next = c.Position + 1
+		}
+	}
+	return next
+}
+
+func (t *Table) Clone() *Table {
+	t2 := NewTable()
+	for name, c := range t.Columns {
+		t2.Columns[name] = c.Clone()
+	}
+	if t.PrimaryKey != nil {
+		t2.PrimaryKey = t.PrimaryKey.Clone()
+	}
+	for name, i := range t.Indexes {
+		t2.Indexes[name] = i.Clone()
+	}
+	for name, u := range t.Uniques {
+		t2.Uniques[name] = u.Clone()
+	}
+	for name, fk := range t.ForeignKeys {
+		t2.ForeignKeys[name] = fk.Clone()
+	}
+	for name, c := range t.Checks {
+		t2.Checks[name] = c.Clone()
 	}
+	return t2
 }